@@ -57,6 +57,14 @@ const (
 	VerbDeny  ArgoVerb = "deny"
 )
 
+// Create enums for the scope of translated roles
+type ArgoRoleScope string
+
+const (
+	RoleScopeGlobal  ArgoRoleScope = "global"
+	RoleScopeProject ArgoRoleScope = "project"
+)
+
 type ArgocdPolicyDefinition struct {
 	// Name for permission mapping
 	Resource ArgoResource `json:"resource,omitempty"`
@@ -1,9 +1,18 @@
 package v1alpha1
 
 import (
+	"fmt"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"golang.org/x/time/rate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/workqueue"
 )
 
 // Assign Tenants to the ArgoTranslator
@@ -18,3 +27,184 @@ func (in *ArgoAddonSpec) ProxyServiceString(tenant *capsulev1beta2.Tenant) strin
 		in.Proxy.CapsuleProxyServiceNamespace + ".svc:" +
 		strconv.Itoa(int(in.Proxy.CapsuleProxyServicePort))
 }
+
+// Get the ArgoCD configuration of a named instance, the default instance is returned for an empty name
+func (in *ArgoAddonSpec) GetInstance(name string) (*ControllerArgoCDConfig, error) {
+	if name == "" {
+		return &in.Argo, nil
+	}
+
+	for idx := range in.Instances {
+		if in.Instances[idx].Name == name {
+			return &in.Instances[idx].ControllerArgoCDConfig, nil
+		}
+	}
+
+	return nil, fmt.Errorf("argocd instance %s is not configured", name)
+}
+
+// Names of all instances, including the default instance (empty name)
+func (in *ArgoAddonSpec) InstanceNames() []string {
+	names := []string{""}
+	for _, instance := range in.Instances {
+		names = append(names, instance.Name)
+	}
+
+	return names
+}
+
+// Settings with the ArgoCD configuration of the given instance
+func (in *ArgoAddonSpec) ForInstance(name string) (*ArgoAddonSpec, error) {
+	instance, err := in.GetInstance(name)
+	if err != nil {
+		return nil, err
+	}
+
+	spec := *in
+	spec.Argo = *instance
+
+	return &spec, nil
+}
+
+// Settings with the ArgoCD configuration of the instance managing the tenant. If the instance
+// is no longer configured, the default instance is used
+func (in *ArgoAddonSpec) ForTenant(tenant *capsulev1beta2.Tenant) *ArgoAddonSpec {
+	spec, err := in.ForInstance(meta.TenantManagedInstance(tenant))
+	if err != nil {
+		return in
+	}
+
+	return spec
+}
+
+// Namespaces in which the Secrets, Services and ServiceAccounts are cached without label selector. These are the
+// namespaces of the argocd instances, the ServiceAccount and proxy namespace and the namespaces allowed for lookup
+func (in *ArgoAddonSpec) CacheNamespaces() (namespaces []string) {
+	candidates := []string{in.Argo.Namespace, in.Proxy.ServiceAccountNamespace, in.Proxy.CapsuleProxyServiceNamespace}
+	for _, instance := range in.Instances {
+		candidates = append(candidates, instance.Namespace)
+	}
+
+	candidates = append(candidates, in.Lookup.Namespaces...)
+
+	for _, namespace := range candidates {
+		if namespace != "" && !utils.ContainsString(namespaces, namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// Rate limiter for the work queue of the controller, with the controller-runtime defaults for unset values
+func (in *ControllerOptions) RateLimiter() workqueue.RateLimiter {
+	baseDelay, maxDelay := 5*time.Millisecond, 1000*time.Second
+	if in.RateLimit.BaseDelay.Duration > 0 {
+		baseDelay = in.RateLimit.BaseDelay.Duration
+	}
+
+	if in.RateLimit.MaxDelay.Duration > 0 {
+		maxDelay = in.RateLimit.MaxDelay.Duration
+	}
+
+	qps, burst := 10, 100
+	if in.RateLimit.QPS > 0 {
+		qps = int(in.RateLimit.QPS)
+	}
+
+	if in.RateLimit.Burst > 0 {
+		burst = int(in.RateLimit.Burst)
+	}
+
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(baseDelay, maxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(qps), burst)},
+	)
+}
+
+// Interval after which all objects are reconciled again, the fallback applies when no interval is set
+func (in *ControllerOptions) Resync(fallback time.Duration) time.Duration {
+	if in.ResyncInterval.Duration <= 0 {
+		return fallback
+	}
+
+	return in.ResyncInterval.Duration
+}
+
+// Maximum number of parallel reconciles, at least one
+func (in *ControllerOptions) Concurrency() int {
+	if in.MaxConcurrentReconciles < 1 {
+		return 1
+	}
+
+	return in.MaxConcurrentReconciles
+}
+
+// Namespace and name of the ServiceAccount of the tenant. The namespace can be overwritten on tenant-basis
+func (in *ArgoAddonSpec) TenantServiceAccount(tenant *capsulev1beta2.Tenant) (namespace string, name string) {
+	namespace = in.Proxy.ServiceAccountNamespace
+	if ns := meta.TenantServiceAccountNamespace(tenant); ns != "" {
+		namespace = ns
+	}
+
+	return namespace, tenant.Name
+}
+
+// Returns true if the tenant is reconciled by this controller
+func (in *ArgoAddonSpec) SelectsTenant(tenant *capsulev1beta2.Tenant) bool {
+	return selects(in.TenantSelector, tenant.GetLabels())
+}
+
+// Returns true if the translator is used by this controller
+func (in *ArgoAddonSpec) SelectsTranslator(translator *ArgoTranslator) bool {
+	return selects(in.TranslatorSelector, translator.GetLabels())
+}
+
+// An empty selector selects everything, an invalid selector nothing
+func selects(selector *metav1.LabelSelector, set map[string]string) bool {
+	if selector == nil {
+		return true
+	}
+
+	sel, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+
+	return sel.Matches(labels.Set(set))
+}
+
+// Returns true if the resource can be read with lookup. Namespaced resources must be read from an allowed
+// namespace, cluster-scoped resources are read without namespace
+func (in *ArgoAddonSpec) LookupAllowed(apiVersion string, kind string, namespace string) bool {
+	allowed := false
+	for _, resource := range in.Lookup.Resources {
+		if resource.APIVersion == apiVersion && resource.Kind == kind {
+			allowed = true
+
+			break
+		}
+	}
+
+	if !allowed {
+		return false
+	}
+
+	return namespace == "" || utils.ContainsString(in.Lookup.Namespaces, namespace)
+}
+
+// Deletion policy for the tenant, a valid policy from the tenant annotation takes precedence
+func (in *ArgoAddonSpec) TenantDeletionPolicy(tenant *capsulev1beta2.Tenant) DeletionPolicy {
+	switch policy := DeletionPolicy(meta.TenantDeletionPolicy(tenant)); policy {
+	case DeletionPolicyIgnore, DeletionPolicyBlock, DeletionPolicyCascade, DeletionPolicyOrphan:
+		return policy
+	}
+
+	if in.Deletion.Policy == "" {
+		return DeletionPolicyIgnore
+	}
+
+	return in.Deletion.Policy
+}
@@ -20,4 +20,7 @@ package v1alpha1
 type ArgoAddonStatus struct {
 	// Last applied valid configuration
 	Config ArgoAddonSpec `json:"loaded,omitempty"`
+
+	// Options the controllers were set up with. They differ from the spec until the controller is restarted
+	Controllers *ControllersConfig `json:"controllers,omitempty"`
 }
@@ -36,12 +36,52 @@ type ArgoAddonSpec struct {
 	Proxy ControllerCapsuleProxyConfig `json:"proxy,omitempty"`
 
 	// ArgoCD configuration
-	// +kubebuilder:default={namespace: argocd, rbacConfigMap: argocd-rbac-cm}
+	// +kubebuilder:default={namespace: argocd, rbacConfigMap: argocd-rbac-cm, cmdParamsConfigMap: argocd-cmd-params-cm, configMap: argocd-cm, secret: argocd-secret}
 	Argo ControllerArgoCDConfig `json:"argo,omitempty"`
 
+	// Additional named ArgoCD instances. Tenants are assigned to an instance with the annotation
+	// "argo.addons.projectcapsule.dev/instance" or the instance of their translators. Tenants without
+	// an instance are reconciled into the default instance (argo).
+	//+kubebuilder:optional
+	Instances []ControllerArgoCDInstance `json:"instances,omitempty"`
+
+	// Remote member clusters the tenants span. For each cluster and tenant, the ServiceAccount, its token and the capsule-proxy
+	// service are created on the remote cluster, an argo cluster secret is written and a destination is added to the appproject.
+	//+kubebuilder:optional
+	Clusters []ControllerRemoteCluster `json:"clusters,omitempty"`
+
+	// Handling of the Applications in the appproject of a tenant, when the tenant is deleted. The policy can be overwritten
+	// on tenant-basis with the annotation "argo.addons.projectcapsule.dev/deletion-policy"
+	// +kubebuilder:default={policy: Ignore}
+	Deletion ControllerDeletionConfig `json:"deletion,omitempty"`
+
+	// Reaction to cordoned tenants (Capsule). By default a sync window denying all syncs to the tenant namespaces is injected
+	// into the appproject, while the tenant is cordoned.
+	// +kubebuilder:default={syncWindow: true, readOnly: false}
+	Cordoning ControllerCordoningConfig `json:"cordoning,omitempty"`
+
+	// Objects which can be read with the lookup function in templates. Nothing can be read by default.
+	//+kubebuilder:optional
+	Lookup ControllerLookupConfig `json:"lookup,omitempty"`
+
+	// Sandbox for the rendering of templates. Functions exposing the environment of the controller and
+	// non-deterministic functions are not available, unless they are allowed again.
+	// +kubebuilder:default={timeout: "5s", maxOutputSize: 1048576}
+	Templating ControllerTemplatingConfig `json:"templating,omitempty"`
+
+	// Concurrency and pacing of the controllers. The options are applied when the controllers are set up,
+	// changes require a restart of the controller.
+	// +kubebuilder:default={tenant: {maxConcurrentReconciles: 1, resyncInterval: "1h"}, translator: {maxConcurrentReconciles: 1}}
+	Controllers ControllersConfig `json:"controllers,omitempty"`
+
+	// Tenant selector. Only tenants matching this selector are reconciled by this controller, if empty all tenants are reconciled.
+	// Allows to run multiple controllers (with different settings) side by side.
+	// +optional
+	TenantSelector *metav1.LabelSelector `json:"tenantSelector,omitempty"`
+
 	// Translator selector. Only translators matching this selector will be used for this controller, if empty all translators will be used.
 	// +optional
-	//TranslatorSelector *metav1.LabelSelector `json:"translatorSelector,omitempty"`
+	TranslatorSelector *metav1.LabelSelector `json:"translatorSelector,omitempty"`
 }
 
 // Controller Configuration for ArgoCD
@@ -72,13 +112,268 @@ type ControllerCapsuleProxyConfig struct {
 	ServiceAccountNamespace string `json:"serviceAccountNamespace,omitempty"`
 }
 
+// Lookup Configuration for templates
+type ControllerLookupConfig struct {
+	// Resources (apiVersion and kind) which can be read with lookup
+	//+kubebuilder:optional
+	Resources []ControllerLookupResource `json:"resources,omitempty"`
+
+	// Namespaces from which namespaced resources can be read with lookup
+	//+kubebuilder:optional
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// Templating Configuration
+type ControllerTemplatingConfig struct {
+	// Restricted functions which are made available to templates again (eg. "now" or "uuidv4"). Restricted are the
+	// functions reading the environment ("env", "expandenv", "getHostByName") and time-dependent or random functions,
+	// as their output changes on every reconcile.
+	//+kubebuilder:optional
+	Functions []string `json:"functions,omitempty"`
+
+	// Maximum duration for the rendering of a single template
+	// +kubebuilder:default="5s"
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// Maximum size of the output of a single template in bytes
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1048576
+	MaxOutputSize int64 `json:"maxOutputSize,omitempty"`
+}
+
+// Tuning of the controllers
+type ControllersConfig struct {
+	// Options for the tenant controller. The tenants are reconciled again after the resync interval, even when
+	// their inputs did not change.
+	// +kubebuilder:default={maxConcurrentReconciles: 1, resyncInterval: "1h"}
+	Tenant ControllerOptions `json:"tenant,omitempty"`
+
+	// Options for the translator controller
+	// +kubebuilder:default={maxConcurrentReconciles: 1}
+	Translator ControllerOptions `json:"translator,omitempty"`
+}
+
+// Options of a single controller
+type ControllerOptions struct {
+	// Maximum number of reconciles which run in parallel
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+
+	// Rate limits of the work queue, unset values fall back to the controller-runtime defaults
+	//+kubebuilder:optional
+	RateLimit ControllerRateLimit `json:"rateLimit,omitempty"`
+
+	// Interval after which all objects are reconciled again, even when nothing changed. Defaults to 1h for the tenant
+	// controller, disabled for the translator controller when empty
+	//+kubebuilder:optional
+	ResyncInterval metav1.Duration `json:"resyncInterval,omitempty"`
+}
+
+// Rate limits of a work queue. Failed items are retried with an exponential backoff, all items are limited
+// by an overall token bucket
+type ControllerRateLimit struct {
+	// Delay of the first retry of a failed item (default 5ms)
+	//+kubebuilder:optional
+	BaseDelay metav1.Duration `json:"baseDelay,omitempty"`
+
+	// Maximum delay between retries of a failed item (default 1000s)
+	//+kubebuilder:optional
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+
+	// Overall number of items processed per second (default 10)
+	// +kubebuilder:validation:Minimum=0
+	//+kubebuilder:optional
+	QPS int32 `json:"qps,omitempty"`
+
+	// Burst of items processed above the overall rate (default 100)
+	// +kubebuilder:validation:Minimum=0
+	//+kubebuilder:optional
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// Resource which can be read with lookup
+type ControllerLookupResource struct {
+	// APIVersion of the resource (eg. "v1" or "apps/v1")
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resource (eg. "ConfigMap")
+	Kind string `json:"kind"`
+}
+
+// Cordoning Configuration for tenants
+type ControllerCordoningConfig struct {
+	// Inject a deny sync window for the tenant namespaces into the appprojects of cordoned tenants
+	// +kubebuilder:default=true
+	SyncWindow bool `json:"syncWindow,omitempty"`
+
+	// Demote the owners of the appprojects to read-only in the policies, while the tenant is cordoned
+	// +kubebuilder:default=false
+	ReadOnly bool `json:"readOnly,omitempty"`
+}
+
+// Create enums for the handling of Applications when a tenant is deleted
+type DeletionPolicy string
+
+const (
+	// The appproject is removed regardless of the Applications, which are left in an error state
+	DeletionPolicyIgnore DeletionPolicy = "Ignore"
+
+	// The tenant is not removed while Applications exist in its appproject
+	DeletionPolicyBlock DeletionPolicy = "Block"
+
+	// The Applications are deleted (including their resources) before the tenant is removed
+	DeletionPolicyCascade DeletionPolicy = "Cascade"
+
+	// The Applications are moved to the quarantine project before the tenant is removed
+	DeletionPolicyOrphan DeletionPolicy = "Orphan"
+)
+
+// Deletion Configuration for tenants
+// +kubebuilder:validation:XValidation:rule="!has(self.policy) || self.policy != 'Orphan' || (has(self.quarantineProject) && size(self.quarantineProject) > 0)",message="quarantineProject is required for the policy Orphan"
+type ControllerDeletionConfig struct {
+	// Policy for the Applications in the appproject of a deleted tenant. "Ignore" removes the appproject regardless of the
+	// Applications, "Block" keeps the tenant until all Applications are removed, "Cascade" deletes the Applications with
+	// their resources and "Orphan" moves the Applications to the quarantine project.
+	// +kubebuilder:validation:Enum=Ignore;Block;Cascade;Orphan
+	// +kubebuilder:default=Ignore
+	Policy DeletionPolicy `json:"policy,omitempty"`
+
+	// AppProject (in the argocd namespace) the Applications are moved to with the policy "Orphan". Required for the
+	// policy "Orphan", the AppProject must not allow wildcard destinations.
+	//+kubebuilder:optional
+	QuarantineProject string `json:"quarantineProject,omitempty"`
+}
+
+// Remote member cluster
+type ControllerRemoteCluster struct {
+	// Name of the cluster. The argo cluster of a tenant is named "<tenant>-<name>"
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Secret containing the kubeconfig for the remote cluster
+	KubeConfig ControllerSecretKeyReference `json:"kubeconfig"`
+
+	// URL of the capsule-proxy on the remote cluster, as reachable from ArgoCD. The certificate of the capsule-proxy
+	// is verified with the certificate authority of the cluster entry in the kubeconfig.
+	// +kubebuilder:validation:MinLength=1
+	Server string `json:"server"`
+
+	// Namespace on the remote cluster where the tenant ServiceAccounts are created.
+	// Defaults to the serviceAccountNamespace of the proxy configuration, can be overwritten on tenant-basis
+	//+kubebuilder:optional
+	ServiceAccountNamespace string `json:"serviceAccountNamespace,omitempty"`
+}
+
+// Reference to a key of a Secret
+type ControllerSecretKeyReference struct {
+	// Name of the secret
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace of the secret
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// Key within the secret
+	// +kubebuilder:default=kubeconfig
+	Key string `json:"key,omitempty"`
+}
+
+// Named ArgoCD instance
+type ControllerArgoCDInstance struct {
+	// Name of the instance, referenced by tenants and translators
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// ArgoCD configuration of the instance
+	ControllerArgoCDConfig `json:",inline"`
+}
+
 // Controller Configuration for ArgoCD
 type ControllerArgoCDConfig struct {
 	// Namespace where the ArgoCD instance is running
+	// +kubebuilder:default=argocd
 	Namespace string `json:"namespace,omitempty"`
 
 	// Name of the ArgoCD rbac configmap (required for the controller)
+	// +kubebuilder:default=argocd-rbac-cm
 	RBACConfigMap string `json:"rbacConfigMap,omitempty"`
+
+	// Target where the tenant policies are reflected. By default the policies are written to the rbac configmap
+	// +kubebuilder:default={}
+	RBACTarget ControllerRBACTargetConfig `json:"rbacTarget,omitempty"`
+
+	// Name of the ArgoCD configmap. Only required when translators define accounts
+	// +kubebuilder:default=argocd-cm
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// Name of the ArgoCD secret, used to sign and register account tokens
+	// +kubebuilder:default=argocd-secret
+	Secret string `json:"secret,omitempty"`
+
+	// Name of the ArgoCD command parameters configmap. Only required when source namespaces are managed
+	// +kubebuilder:default=argocd-cmd-params-cm
+	CmdParamsConfigMap string `json:"cmdParamsConfigMap,omitempty"`
+
+	// Allow tenants to manage their Applications and ApplicationSets from within their own namespaces
+	// +kubebuilder:default={}
+	SourceNamespaces ControllerSourceNamespacesConfig `json:"sourceNamespaces,omitempty"`
+
+	// Allow tenants to provide repository credentials for their project from within their own namespaces
+	// +kubebuilder:default={}
+	Repositories ControllerRepositoriesConfig `json:"repositories,omitempty"`
+}
+
+// Create enums for the targets of the tenant policies
+type RBACTargetKind string
+
+const (
+	// Policies are written as policy.<project>.csv to the rbac configmap
+	RBACTargetConfigMap RBACTargetKind = "ConfigMap"
+
+	// Policies are written to a managed section of spec.rbac.policy of the argocd-operator ArgoCD resource
+	RBACTargetArgoCD RBACTargetKind = "ArgoCD"
+)
+
+// RBAC Target Configuration for ArgoCD
+type ControllerRBACTargetConfig struct {
+	// Kind of the target. "ConfigMap" writes the policies to the rbac configmap (policy.<project>.csv).
+	// "ArgoCD" writes the policies to a managed section of spec.rbac.policy of the ArgoCD resource (argocd-operator, OpenShift GitOps),
+	// which otherwise overwrites the rbac configmap.
+	// +kubebuilder:validation:Enum=ConfigMap;ArgoCD
+	// +kubebuilder:default=ConfigMap
+	Kind RBACTargetKind `json:"kind,omitempty"`
+
+	// Name of the ArgoCD resource in the argocd namespace. Only used for target "ArgoCD"
+	// +kubebuilder:default=argocd
+	Name string `json:"name,omitempty"`
+}
+
+// Repository Configuration for ArgoCD (Project-scoped repositories)
+type ControllerRepositoriesConfig struct {
+	// Enable reflection of repository secrets. Secrets in tenant namespaces labeled with
+	// "argo.addons.projectcapsule.dev/repository" (value "repository" or "repo-creds") are copied into the argocd namespace
+	// and scoped to the tenant's project. The url must be permitted by the sourceRepos of the project.
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+}
+
+// Source Namespaces Configuration for ArgoCD (Apps in any namespace)
+type ControllerSourceNamespacesConfig struct {
+	// Enable source namespaces for tenant appprojects. The tenant namespaces are added as sourceNamespaces to the
+	// appproject and the argocd command parameters are kept up to date with all tenant namespaces.
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+
+	// Instead of listing every namespace of a tenant, a glob pattern is used as source namespace (eg. "{{ .Tenant.Name }}-*").
+	// You can use Sprig Templating with this field, the same context as for the translators is available.
+	//+kubebuilder:optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Also reconcile the namespaces for the applicationset controller
+	// +kubebuilder:default=true
+	ApplicationSets bool `json:"applicationSets,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,65 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ArgoTemplateLibrarySpec defines the named templates of the library
+type ArgoTemplateLibrarySpec struct {
+	// Named templates, which can be rendered with include "<name>" in the templates of translators
+	// referencing the library
+	//+kubebuilder:optional
+	Templates []ArgoNamedTemplate `json:"templates,omitempty"`
+}
+
+// Named template (equivalent to {{ define "<name>" }}...{{ end }})
+type ArgoNamedTemplate struct {
+	// Name of the template, must be unique across the libraries referenced by a translator
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Template body, you can use Sprig Templating (including include and tpl)
+	Template string `json:"template"`
+}
+
+//nolint:lll
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// ArgoTemplateLibrary is the Schema for the argotemplatelibraries API
+type ArgoTemplateLibrary struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ArgoTemplateLibrarySpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ArgoTemplateLibraryList contains a list of ArgoTemplateLibrary
+type ArgoTemplateLibraryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ArgoTemplateLibrary `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ArgoTemplateLibrary{}, &ArgoTemplateLibraryList{})
+}
@@ -17,15 +17,21 @@ limitations under the License.
 package v1alpha1
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"text/template"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"dario.cat/mergo"
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/google/cel-go/cel"
+	"github.com/peak-scale/capsule-argo-addon/internal/expression"
 	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
 	"github.com/peak-scale/capsule-argo-addon/internal/utils"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
 )
@@ -50,34 +56,30 @@ func (t *ArgocdProjectProperties) GetConfig(
 	data interface{},
 	funcmap template.FuncMap,
 ) (props ArgocdProjectStructuredProperties, err error) {
-	props = t.Structured
-
-	// Get Templated config
-	templated, err := t.RenderTemplate(data, funcmap)
+	tmpl, err := template.New("argoTemplate").Funcs(funcmap).Parse(t.Template)
 	if err != nil {
-		return props, fmt.Errorf("error executing template: %w", err)
+		return t.Structured, fmt.Errorf("error parsing template: %w", err)
 	}
 
-	// Use mergo.Merge to merge prop2 into merged (prop1), with overwrite enabled
-	err = mergo.Merge(&props, templated, mergo.WithAppendSlice)
-
-	return
+	return t.GetConfigFrom(tmpl, data)
 }
 
-// Get Combined Configuration from structured and Template
-func (t *ArgocdProjectProperties) GetConfigs(
+// Get Combined Configuration from structured and the parsed Template
+func (t *ArgocdProjectProperties) GetConfigFrom(
+	tmpl *template.Template,
 	data interface{},
-	funcmap template.FuncMap,
-) (structured ArgocdProjectStructuredProperties, templated ArgocdProjectStructuredProperties, err error) {
-	structured = t.Structured
+) (props ArgocdProjectStructuredProperties, err error) {
+	props = *t.Structured.DeepCopy()
 
 	// Get Templated config
-	templated, err = t.RenderTemplate(data, funcmap)
+	templated, err := t.RenderTemplateFrom(tmpl, data)
 	if err != nil {
-		fmt.Println("Error rendering template:", err)
-		return
+		return props, fmt.Errorf("error executing template: %w", err)
 	}
 
+	// Use mergo.Merge to merge prop2 into merged (prop1), with overwrite enabled
+	err = mergo.Merge(&props, templated, mergo.WithAppendSlice)
+
 	return
 }
 
@@ -86,21 +88,28 @@ func (t *ArgocdProjectProperties) RenderTemplate(
 	data interface{},
 	funcmap template.FuncMap,
 ) (ArgocdProjectStructuredProperties, error) {
-	var structuredProperties ArgocdProjectStructuredProperties
 	// Parse and execute the template using sprig functions
 	tmpl, err := template.New("argoTemplate").Funcs(funcmap).Parse(t.Template)
 	if err != nil {
-		return structuredProperties, fmt.Errorf("error parsing template: %w", err)
+		return ArgocdProjectStructuredProperties{}, fmt.Errorf("error parsing template: %w", err)
 	}
 
-	var rendered bytes.Buffer
-	err = tmpl.Execute(&rendered, data)
+	return t.RenderTemplateFrom(tmpl, data)
+}
+
+// Executes the parsed template of the ArgoCD project properties
+func (t *ArgocdProjectProperties) RenderTemplateFrom(
+	tmpl *template.Template,
+	data interface{},
+) (ArgocdProjectStructuredProperties, error) {
+	var structuredProperties ArgocdProjectStructuredProperties
+
+	rendered, err := sandbox.Execute(tmpl, data)
 	if err != nil {
 		return structuredProperties, fmt.Errorf("error executing template: %w", err)
 	}
 
-	yamlBytes := rendered.Bytes()
-	jsonBytes, err := utils.YamlToJSON(yamlBytes)
+	jsonBytes, err := utils.YamlToJSON(rendered)
 	if err != nil {
 		return structuredProperties, fmt.Errorf("error converting yaml to json: %w", err)
 	}
@@ -113,6 +122,157 @@ func (t *ArgocdProjectProperties) RenderTemplate(
 	return structuredProperties, nil
 }
 
+// Renders the template into an Application or ApplicationSet
+func (t *ArgocdApplicationTemplate) Render(
+	data interface{},
+	funcmap template.FuncMap,
+) (client.Object, error) {
+	tmpl, err := template.New(t.Name).Funcs(funcmap).Parse(t.Template)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template: %w", err)
+	}
+
+	return t.RenderFrom(tmpl, data)
+}
+
+// Renders the parsed template into an Application or ApplicationSet
+func (t *ArgocdApplicationTemplate) RenderFrom(
+	tmpl *template.Template,
+	data interface{},
+) (client.Object, error) {
+	rendered, err := sandbox.Execute(tmpl, data)
+	if err != nil {
+		return nil, fmt.Errorf("error executing template: %w", err)
+	}
+
+	jsonBytes, err := utils.YamlToJSON(rendered)
+	if err != nil {
+		return nil, fmt.Errorf("error converting yaml to json: %w", err)
+	}
+
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(jsonBytes, &typeMeta); err != nil {
+		return nil, fmt.Errorf("error unmarshaling json: %w", err)
+	}
+
+	if typeMeta.APIVersion != argocdv1alpha1.SchemeGroupVersion.String() {
+		return nil, fmt.Errorf("unsupported apiVersion %q for application template %s", typeMeta.APIVersion, t.Name)
+	}
+
+	var obj client.Object
+	switch typeMeta.Kind {
+	case argocdv1alpha1.ApplicationSchemaGroupVersionKind.Kind:
+		obj = &argocdv1alpha1.Application{}
+	case argocdv1alpha1.ApplicationSetSchemaGroupVersionKind.Kind:
+		obj = &argocdv1alpha1.ApplicationSet{}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q for application template %s", typeMeta.Kind, t.Name)
+	}
+
+	if err := json.Unmarshal(jsonBytes, obj); err != nil {
+		return nil, fmt.Errorf("error unmarshaling json: %w", err)
+	}
+
+	if obj.GetName() == "" {
+		return nil, fmt.Errorf("application template %s must render a name", t.Name)
+	}
+
+	return obj, nil
+}
+
+// Duration until the token expires, zero if the token does not expire
+func (t *ArgocdAccountToken) GetExpiresIn() time.Duration {
+	if t.ExpiresIn == nil {
+		return 0
+	}
+
+	return t.ExpiresIn.Duration
+}
+
+// Role is added to the appproject instead of the argocd rbac configmap
+func (r *ArgocdProjectRolesTranslator) IsProjectScoped() bool {
+	return r.Scope == RoleScopeProject
+}
+
+// Get a role of the translator by name
+func (in *ArgoTranslator) GetProjectRole(name string) *ArgocdProjectRolesTranslator {
+	for idx := range in.Spec.ProjectRoles {
+		if in.Spec.ProjectRoles[idx].Name == name {
+			return &in.Spec.ProjectRoles[idx]
+		}
+	}
+
+	return nil
+}
+
+// Verify if the translator applies to the tenant. Translators excluded by the tenant are never applied, translators
+// included by the tenant are applied without matching the selector. Otherwise the selector must match the tenant's labels.
+func (in *ArgoTranslator) SelectsTenant(tenant *capsulev1beta2.Tenant) (bool, error) {
+	if utils.ContainsString(meta.TenantExcludedTranslators(tenant), in.Name) {
+		return false, nil
+	}
+
+	if utils.ContainsString(meta.TenantIncludedTranslators(tenant), in.Name) {
+		return true, nil
+	}
+
+	if in.Spec.Selector == nil && in.Spec.MatchExpression == "" {
+		return false, nil
+	}
+
+	if in.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(in.Spec.Selector)
+		if err != nil {
+			return false, err
+		}
+
+		if !selector.Matches(labels.Set(tenant.Labels)) {
+			return false, nil
+		}
+	}
+
+	if in.Spec.MatchExpression == "" {
+		return true, nil
+	}
+
+	program, err := in.CompileMatchExpression()
+	if err != nil {
+		return false, err
+	}
+
+	// Tenants which can't be evaluated are not selected
+	matches, err := expression.Matches(program, tenant)
+	if err != nil {
+		return false, nil
+	}
+
+	return matches, nil
+}
+
+// Compiled match expression, it's only compiled once per generation of the translator
+func (in *ArgoTranslator) CompileMatchExpression() (cel.Program, error) {
+	program, err := expression.Program(in.UID, in.Generation, in.Spec.MatchExpression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid match expression: %w", err)
+	}
+
+	return program, nil
+}
+
+// Values of the translator, empty if no values are defined
+func (in *ArgoTranslator) GetValues() (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if in.Spec.Values == nil || len(in.Spec.Values.Raw) == 0 {
+		return values, nil
+	}
+
+	if err := json.Unmarshal(in.Spec.Values.Raw, &values); err != nil {
+		return nil, fmt.Errorf("invalid values: %w", err)
+	}
+
+	return values, nil
+}
+
 // Assign Tenants to the ArgoTranslator
 func (in *ArgoTranslator) GetTenants() []TenantStatus {
 	return in.Status.Tenants
@@ -146,6 +306,13 @@ func (in *ArgoTranslator) updateReadyStatus() {
 			return // Exit early if any tenant is not ready
 		}
 	}
+
+	for _, condition := range in.Status.Conditions {
+		if condition.Status != metav1.ConditionTrue {
+			in.Status.Ready = meta.NotReadyCondition
+			return
+		}
+	}
 }
 
 // Update the condition for a single Tenant
@@ -30,6 +30,8 @@ type ArgoTranslatorStatus struct {
 	Size uint `json:"size,omitempty"`
 	// Ready field indicating overall readiness of the translator
 	Ready string `json:"ready,omitempty"`
+	// Conditions of the translator itself (eg. compilation of the match expression)
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 type TenantStatus struct {
@@ -19,6 +19,7 @@ package v1alpha1
 import (
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -29,6 +30,17 @@ type ArgoTranslatorSpec struct {
 	// Selector to match tenants which are used for the translator
 	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 
+	// CEL expression evaluated against the tenant (available as "tenant"), which must evaluate to a bool. When
+	// combined with the selector, both must match. Tenants for which the expression can't be evaluated
+	// (eg. absent fields, use has()) are not selected.
+	//+kubebuilder:optional
+	MatchExpression string `json:"matchExpression,omitempty"`
+
+	// Named ArgoCD instance (from the ArgoAddon configuration) the selected tenants are reconciled into.
+	// The annotation "argo.addons.projectcapsule.dev/instance" on the tenant takes precedence.
+	//+kubebuilder:optional
+	Instance string `json:"instance,omitempty"`
+
 	// Application-Project Roles for the tenant
 	//+kubebuilder:optional
 	ProjectRoles []ArgocdProjectRolesTranslator `json:"roles,omitempty"`
@@ -37,10 +49,118 @@ type ArgoTranslatorSpec struct {
 	//+kubebuilder:optional
 	ProjectSettings ArgocdProjectProperties `json:"settings,omitempty"`
 
+	// Additional appprojects which are created for each tenant (eg. per environment). The appprojects are named
+	// "<project>-<name>" and get their own policy (policy.<project>-<name>.csv).
+	//+kubebuilder:optional
+	Projects []ArgocdProjectVariant `json:"projects,omitempty"`
+
+	// Template libraries (ArgoTemplateLibrary) whose named templates are available with include in the
+	// templates of the translator
+	//+kubebuilder:optional
+	Libraries []string `json:"libraries,omitempty"`
+
+	// Values for the templates of the translator, available as .Values (similar to helm values)
+	//+kubebuilder:optional
+	//+kubebuilder:pruning:PreserveUnknownFields
+	Values *runtime.RawExtension `json:"values,omitempty"`
+
+	// Keys of the values (dot separated, eg. "resources.limits") which tenants may override
+	//+kubebuilder:optional
+	OverridableValues []string `json:"overridableValues,omitempty"`
+
 	// In this field you can define custom policies. It must result in a valid argocd policy format (CSV)
-	// You can use Sprig Templating with this field
+	// You can use Sprig Templating with this field (including .Values)
 	//+kubebuilder:optional
 	CustomPolicy string `json:"customPolicy,omitempty"`
+
+	// Applications and ApplicationSets which are created for each tenant in the tenant's project
+	//+kubebuilder:optional
+	Applications []ArgocdApplicationTemplate `json:"applications,omitempty"`
+
+	// Local accounts (apiKey) which are created for each tenant, eg. for CI pipelines
+	//+kubebuilder:optional
+	Accounts []ArgocdAccountTranslator `json:"accounts,omitempty"`
+}
+
+// Additional appproject for a tenant
+type ArgocdProjectVariant struct {
+	// Name of the variant. The appproject is named "<project>-<name>"
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Settings for the appproject of the variant. The settings of the translator are not inherited
+	//+kubebuilder:optional
+	ProjectSettings ArgocdProjectProperties `json:"settings,omitempty"`
+
+	// Roles for the appproject of the variant, reflected in the policy of the variant
+	//+kubebuilder:optional
+	ProjectRoles []ArgocdProjectVariantRole `json:"roles,omitempty"`
+
+	// Only tenant namespaces matching the selector are added as destinations. If not set, all namespaces
+	// of the tenant are permitted. Destinations are only added when the capsule-proxy integration is enabled.
+	//+kubebuilder:optional
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// Role for the appproject of a variant
+type ArgocdProjectVariantRole struct {
+	// Name for permission mapping
+	Name string `json:"name"`
+
+	// TenantRoles selects tenant users based on their cluster roles to this Permission
+	ClusterRoles []string `json:"clusterRoles,omitempty"`
+
+	// Roles are reflected in the policy of the variant
+	Policies []ArgocdPolicyDefinition `json:"policies,omitempty"`
+
+	// Define if the selected users are owners of the appproject of the variant. By default the selected users get
+	// read-only access to the appproject.
+	// +kubebuilder:default=false
+	Owner bool `json:"owner,omitempty"`
+}
+
+// Local ArgoCD account for a tenant
+type ArgocdAccountTranslator struct {
+	// Name of the account. The account is prefixed with the tenant name (<tenant>-<name>)
+	Name string `json:"name"`
+
+	// Roles of this translator (by name) the account is bound to
+	//+kubebuilder:optional
+	Roles []string `json:"roles,omitempty"`
+
+	// Define if the account is owner of the appproject. By default the account gets
+	// read-only access to the project.
+	// +kubebuilder:default=false
+	Owner bool `json:"owner,omitempty"`
+
+	// Mint an API token for the account and store it in a secret within a tenant namespace
+	//+kubebuilder:optional
+	Token *ArgocdAccountToken `json:"token,omitempty"`
+}
+
+// Secret for the API token of a local account or project role
+type ArgocdAccountToken struct {
+	// Namespace of the tenant where the secret is created
+	Namespace string `json:"namespace"`
+
+	// Name of the secret, defaults to the account name
+	//+kubebuilder:optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Duration until the token expires, the token is renewed once expired. If not set the token does not expire
+	//+kubebuilder:optional
+	ExpiresIn *metav1.Duration `json:"expiresIn,omitempty"`
+}
+
+// Templated Application or ApplicationSet for a tenant
+type ArgocdApplicationTemplate struct {
+	// Name of the template, used to identify the template within the translator
+	Name string `json:"name"`
+
+	// Template which renders an Application or ApplicationSet manifest (argoproj.io/v1alpha1).
+	// You can use Sprig Templating with this field (including .Values). The project is always set to the tenant's project.
+	// If no namespace is rendered, the argocd namespace is used.
+	Template string `json:"template"`
 }
 
 // Define Permission mappings for an ArogCD Project
@@ -59,6 +179,17 @@ type ArgocdProjectRolesTranslator struct {
 	// read-only access to the project.
 	// +kubebuilder:default=false
 	Owner bool `json:"owner,omitempty"`
+
+	// Scope of the role. Global roles are reflected in the argocd rbac configmap. Project roles are added as
+	// roles to the appproject (proj:<project>:<name>), selected groups are assigned on the appproject.
+	// +kubebuilder:validation:Enum=global;project
+	// +kubebuilder:default=global
+	Scope ArgoRoleScope `json:"scope,omitempty"`
+
+	// Issue a JWT token for the project role and store it in a secret within a tenant namespace.
+	// Only possible for project roles.
+	//+kubebuilder:optional
+	Token *ArgocdAccountToken `json:"token,omitempty"`
 }
 
 type ArgocdProjectProperties struct {
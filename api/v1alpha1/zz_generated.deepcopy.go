@@ -22,7 +22,7 @@ package v1alpha1
 
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -30,8 +30,8 @@ func (in *ArgoAddon) DeepCopyInto(out *ArgoAddon) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoAddon.
@@ -89,6 +89,31 @@ func (in *ArgoAddonSpec) DeepCopyInto(out *ArgoAddonSpec) {
 	*out = *in
 	out.Proxy = in.Proxy
 	out.Argo = in.Argo
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]ControllerArgoCDInstance, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]ControllerRemoteCluster, len(*in))
+		copy(*out, *in)
+	}
+	out.Deletion = in.Deletion
+	out.Cordoning = in.Cordoning
+	in.Lookup.DeepCopyInto(&out.Lookup)
+	in.Templating.DeepCopyInto(&out.Templating)
+	out.Controllers = in.Controllers
+	if in.TenantSelector != nil {
+		in, out := &in.TenantSelector, &out.TenantSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TranslatorSelector != nil {
+		in, out := &in.TranslatorSelector, &out.TranslatorSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoAddonSpec.
@@ -104,7 +129,12 @@ func (in *ArgoAddonSpec) DeepCopy() *ArgoAddonSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoAddonStatus) DeepCopyInto(out *ArgoAddonStatus) {
 	*out = *in
-	out.Config = in.Config
+	in.Config.DeepCopyInto(&out.Config)
+	if in.Controllers != nil {
+		in, out := &in.Controllers, &out.Controllers
+		*out = new(ControllersConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoAddonStatus.
@@ -117,6 +147,99 @@ func (in *ArgoAddonStatus) DeepCopy() *ArgoAddonStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoNamedTemplate) DeepCopyInto(out *ArgoNamedTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoNamedTemplate.
+func (in *ArgoNamedTemplate) DeepCopy() *ArgoNamedTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoNamedTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoTemplateLibrary) DeepCopyInto(out *ArgoTemplateLibrary) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoTemplateLibrary.
+func (in *ArgoTemplateLibrary) DeepCopy() *ArgoTemplateLibrary {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoTemplateLibrary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArgoTemplateLibrary) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoTemplateLibraryList) DeepCopyInto(out *ArgoTemplateLibraryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ArgoTemplateLibrary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoTemplateLibraryList.
+func (in *ArgoTemplateLibraryList) DeepCopy() *ArgoTemplateLibraryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoTemplateLibraryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ArgoTemplateLibraryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgoTemplateLibrarySpec) DeepCopyInto(out *ArgoTemplateLibrarySpec) {
+	*out = *in
+	if in.Templates != nil {
+		in, out := &in.Templates, &out.Templates
+		*out = make([]ArgoNamedTemplate, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoTemplateLibrarySpec.
+func (in *ArgoTemplateLibrarySpec) DeepCopy() *ArgoTemplateLibrarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgoTemplateLibrarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgoTranslator) DeepCopyInto(out *ArgoTranslator) {
 	*out = *in
@@ -192,6 +315,40 @@ func (in *ArgoTranslatorSpec) DeepCopyInto(out *ArgoTranslatorSpec) {
 		}
 	}
 	in.ProjectSettings.DeepCopyInto(&out.ProjectSettings)
+	if in.Projects != nil {
+		in, out := &in.Projects, &out.Projects
+		*out = make([]ArgocdProjectVariant, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Libraries != nil {
+		in, out := &in.Libraries, &out.Libraries
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OverridableValues != nil {
+		in, out := &in.OverridableValues, &out.OverridableValues
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Applications != nil {
+		in, out := &in.Applications, &out.Applications
+		*out = make([]ArgocdApplicationTemplate, len(*in))
+		copy(*out, *in)
+	}
+	if in.Accounts != nil {
+		in, out := &in.Accounts, &out.Accounts
+		*out = make([]ArgocdAccountTranslator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoTranslatorSpec.
@@ -214,6 +371,13 @@ func (in *ArgoTranslatorStatus) DeepCopyInto(out *ArgoTranslatorStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgoTranslatorStatus.
@@ -226,6 +390,66 @@ func (in *ArgoTranslatorStatus) DeepCopy() *ArgoTranslatorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgocdAccountToken) DeepCopyInto(out *ArgocdAccountToken) {
+	*out = *in
+	if in.ExpiresIn != nil {
+		in, out := &in.ExpiresIn, &out.ExpiresIn
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgocdAccountToken.
+func (in *ArgocdAccountToken) DeepCopy() *ArgocdAccountToken {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgocdAccountToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgocdAccountTranslator) DeepCopyInto(out *ArgocdAccountTranslator) {
+	*out = *in
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(ArgocdAccountToken)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgocdAccountTranslator.
+func (in *ArgocdAccountTranslator) DeepCopy() *ArgocdAccountTranslator {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgocdAccountTranslator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgocdApplicationTemplate) DeepCopyInto(out *ArgocdApplicationTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgocdApplicationTemplate.
+func (in *ArgocdApplicationTemplate) DeepCopy() *ArgocdApplicationTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgocdApplicationTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ArgocdPolicyDefinition) DeepCopyInto(out *ArgocdPolicyDefinition) {
 	*out = *in
@@ -311,6 +535,11 @@ func (in *ArgocdProjectRolesTranslator) DeepCopyInto(out *ArgocdProjectRolesTran
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(ArgocdAccountToken)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgocdProjectRolesTranslator.
@@ -340,9 +569,67 @@ func (in *ArgocdProjectStructuredProperties) DeepCopy() *ArgocdProjectStructured
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgocdProjectVariant) DeepCopyInto(out *ArgocdProjectVariant) {
+	*out = *in
+	in.ProjectSettings.DeepCopyInto(&out.ProjectSettings)
+	if in.ProjectRoles != nil {
+		in, out := &in.ProjectRoles, &out.ProjectRoles
+		*out = make([]ArgocdProjectVariantRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgocdProjectVariant.
+func (in *ArgocdProjectVariant) DeepCopy() *ArgocdProjectVariant {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgocdProjectVariant)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArgocdProjectVariantRole) DeepCopyInto(out *ArgocdProjectVariantRole) {
+	*out = *in
+	if in.ClusterRoles != nil {
+		in, out := &in.ClusterRoles, &out.ClusterRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]ArgocdPolicyDefinition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArgocdProjectVariantRole.
+func (in *ArgocdProjectVariantRole) DeepCopy() *ArgocdProjectVariantRole {
+	if in == nil {
+		return nil
+	}
+	out := new(ArgocdProjectVariantRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControllerArgoCDConfig) DeepCopyInto(out *ControllerArgoCDConfig) {
 	*out = *in
+	out.RBACTarget = in.RBACTarget
+	out.SourceNamespaces = in.SourceNamespaces
+	out.Repositories = in.Repositories
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerArgoCDConfig.
@@ -355,6 +642,22 @@ func (in *ControllerArgoCDConfig) DeepCopy() *ControllerArgoCDConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerArgoCDInstance) DeepCopyInto(out *ControllerArgoCDInstance) {
+	*out = *in
+	out.ControllerArgoCDConfig = in.ControllerArgoCDConfig
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerArgoCDInstance.
+func (in *ControllerArgoCDInstance) DeepCopy() *ControllerArgoCDInstance {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerArgoCDInstance)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControllerCapsuleProxyConfig) DeepCopyInto(out *ControllerCapsuleProxyConfig) {
 	*out = *in
@@ -370,6 +673,224 @@ func (in *ControllerCapsuleProxyConfig) DeepCopy() *ControllerCapsuleProxyConfig
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerCordoningConfig) DeepCopyInto(out *ControllerCordoningConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerCordoningConfig.
+func (in *ControllerCordoningConfig) DeepCopy() *ControllerCordoningConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerCordoningConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerDeletionConfig) DeepCopyInto(out *ControllerDeletionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerDeletionConfig.
+func (in *ControllerDeletionConfig) DeepCopy() *ControllerDeletionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerDeletionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerLookupConfig) DeepCopyInto(out *ControllerLookupConfig) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ControllerLookupResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerLookupConfig.
+func (in *ControllerLookupConfig) DeepCopy() *ControllerLookupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerLookupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerLookupResource) DeepCopyInto(out *ControllerLookupResource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerLookupResource.
+func (in *ControllerLookupResource) DeepCopy() *ControllerLookupResource {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerLookupResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerOptions) DeepCopyInto(out *ControllerOptions) {
+	*out = *in
+	out.RateLimit = in.RateLimit
+	out.ResyncInterval = in.ResyncInterval
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerOptions.
+func (in *ControllerOptions) DeepCopy() *ControllerOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerRBACTargetConfig) DeepCopyInto(out *ControllerRBACTargetConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerRBACTargetConfig.
+func (in *ControllerRBACTargetConfig) DeepCopy() *ControllerRBACTargetConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerRBACTargetConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerRateLimit) DeepCopyInto(out *ControllerRateLimit) {
+	*out = *in
+	out.BaseDelay = in.BaseDelay
+	out.MaxDelay = in.MaxDelay
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerRateLimit.
+func (in *ControllerRateLimit) DeepCopy() *ControllerRateLimit {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerRateLimit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerRemoteCluster) DeepCopyInto(out *ControllerRemoteCluster) {
+	*out = *in
+	out.KubeConfig = in.KubeConfig
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerRemoteCluster.
+func (in *ControllerRemoteCluster) DeepCopy() *ControllerRemoteCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerRemoteCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerRepositoriesConfig) DeepCopyInto(out *ControllerRepositoriesConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerRepositoriesConfig.
+func (in *ControllerRepositoriesConfig) DeepCopy() *ControllerRepositoriesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerRepositoriesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerSecretKeyReference) DeepCopyInto(out *ControllerSecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerSecretKeyReference.
+func (in *ControllerSecretKeyReference) DeepCopy() *ControllerSecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerSecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerSourceNamespacesConfig) DeepCopyInto(out *ControllerSourceNamespacesConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerSourceNamespacesConfig.
+func (in *ControllerSourceNamespacesConfig) DeepCopy() *ControllerSourceNamespacesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerSourceNamespacesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerTemplatingConfig) DeepCopyInto(out *ControllerTemplatingConfig) {
+	*out = *in
+	if in.Functions != nil {
+		in, out := &in.Functions, &out.Functions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerTemplatingConfig.
+func (in *ControllerTemplatingConfig) DeepCopy() *ControllerTemplatingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerTemplatingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllersConfig) DeepCopyInto(out *ControllersConfig) {
+	*out = *in
+	out.Tenant = in.Tenant
+	out.Translator = in.Translator
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllersConfig.
+func (in *ControllersConfig) DeepCopy() *ControllersConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllersConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TenantStatus) DeepCopyInto(out *TenantStatus) {
 	*out = *in
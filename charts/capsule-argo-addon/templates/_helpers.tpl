@@ -90,3 +90,20 @@ Create the jobs fully-qualified Docker image to use
 {{- end }}
 {{- end }}
 
+
+{{/*
+Namespaces in which the controller caches ConfigMaps (comma separated). These are the namespaces of the argocd
+instances, the proxy and lookup namespaces of the configuration and the additional rbac namespaces
+*/}}
+{{- define "rbac.namespaces" -}}
+{{- $cfg := mergeOverwrite (fromYaml (include "config.defaults" $)) (deepCopy (default dict $.Values.config.spec)) -}}
+{{- $argo := default dict $cfg.argo -}}
+{{- $proxy := default dict $cfg.proxy -}}
+{{- $lookup := default dict $cfg.lookup -}}
+{{- $namespaces := list (default "argocd" $argo.namespace) (default "capsule-system" $proxy.serviceNamespace) (default "" $proxy.serviceAccountNamespace) -}}
+{{- range $instance := (default (list) $cfg.instances) }}
+  {{- $namespaces = append $namespaces (default "" $instance.namespace) -}}
+{{- end }}
+{{- $namespaces = concat $namespaces (default (list) $lookup.namespaces) (default (list) $.Values.rbac.namespaces) -}}
+{{- $namespaces | compact | uniq | join "," -}}
+{{- end }}
@@ -24,10 +24,13 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -38,6 +41,7 @@ import (
 	"github.com/peak-scale/capsule-argo-addon/internal/controllers/config"
 	"github.com/peak-scale/capsule-argo-addon/internal/controllers/tenant"
 	"github.com/peak-scale/capsule-argo-addon/internal/controllers/translator"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	"github.com/peak-scale/capsule-argo-addon/internal/metrics"
 	"github.com/peak-scale/capsule-argo-addon/internal/stores"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
@@ -79,12 +83,44 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Scope finalizers and tracking labels to the settings of this instance
+	meta.SetControllerName(settingName)
+
+	store := stores.NewConfigStore()
+
+	settings := &config.ConfigReconciler{
+		Scheme: scheme,
+		Log:    ctrl.Log.WithName("controllers").WithName("Config"),
+		Store:  store,
+		Config: config.ReconcilerConfig{
+			SettingName: settingName,
+		},
+	}
+
+	// The settings are loaded before the manager is created, the cache is scoped to the namespaces of the settings
+	directClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{
+		Scheme: scheme,
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to initialize client")
+		os.Exit(1)
+	}
+
+	if err = settings.Initialize(ctx, directClient); err != nil {
+		setupLog.Error(err, "unable to initialize settings")
+		os.Exit(1)
+	}
+
+	settings.Config.CacheNamespaces = store.Get().CacheNamespaces()
+	settings.Config.Controllers = store.Get().Controllers.DeepCopy()
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions(settings.Config.CacheNamespaces),
 		Metrics:                metricsserver.Options{BindAddress: metricsAddr},
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "fefb3d10.projectcapsule.dev",
+		LeaderElectionID:       leaderElectionID(settingName),
 		PprofBindAddress:       ":8082",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
@@ -104,46 +140,23 @@ func main() {
 	}
 
 	//+kubebuilder:scaffold:builder
-	store := stores.NewConfigStore()
-
 	metricsRecorder := metrics.MustMakeRecorder()
 
-	settings := &config.ConfigReconciler{
-		Client:   mgr.GetClient(),
-		Scheme:   mgr.GetScheme(),
-		Log:      ctrl.Log.WithName("controllers").WithName("Config"),
-		Recorder: mgr.GetEventRecorderFor("config-controller"),
-		Store:    store,
-		Config: config.ReconcilerConfig{
-			SettingName: settingName,
-		},
-	}
-
-	directClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{
-		Scheme: mgr.GetScheme(),
-		Mapper: mgr.GetRESTMapper(),
-	})
-	if err != nil {
-		setupLog.Error(err, "unable to initialize client")
-		os.Exit(1)
-	}
-
-	if err = settings.Initialize(ctx, directClient); err != nil {
-		setupLog.Error(err, "unable to initialize settings")
-		os.Exit(1)
-	}
+	settings.Client = mgr.GetClient()
+	settings.Recorder = mgr.GetEventRecorderFor("config-controller")
 	if err := settings.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Config")
 		os.Exit(1)
 	}
 
 	if err = (&tenant.TenancyController{
-		Client:   mgr.GetClient(),
-		Log:      ctrl.Log.WithName("controllers").WithName("Tenant"),
-		Recorder: mgr.GetEventRecorderFor("tenant-controller"),
-		Scheme:   mgr.GetScheme(),
-		Metrics:  metricsRecorder,
-		Settings: store,
+		Client:    mgr.GetClient(),
+		APIReader: mgr.GetAPIReader(),
+		Log:       ctrl.Log.WithName("controllers").WithName("Tenant"),
+		Recorder:  mgr.GetEventRecorderFor("tenant-controller"),
+		Scheme:    mgr.GetScheme(),
+		Metrics:   metricsRecorder,
+		Settings:  store,
 	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Tenant")
 		os.Exit(1)
@@ -177,3 +190,41 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// Secrets, Services and ServiceAccounts are cached in the given namespaces. In all other namespaces only the
+// objects managed by this controller are cached. ConfigMaps are only cached in the given namespaces
+func cacheOptions(namespaces []string) cache.Options {
+	namespaced := func() map[string]cache.Config {
+		configs := map[string]cache.Config{}
+		for _, namespace := range namespaces {
+			configs[namespace] = cache.Config{}
+		}
+
+		return configs
+	}
+
+	scoped := func() map[string]cache.Config {
+		configs := namespaced()
+		configs[cache.AllNamespaces] = cache.Config{LabelSelector: labels.SelectorFromSet(meta.TrackingLabels())}
+
+		return configs
+	}
+
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}:      {Namespaces: namespaced()},
+			&corev1.Secret{}:         {Namespaces: scoped()},
+			&corev1.Service{}:        {Namespaces: scoped()},
+			&corev1.ServiceAccount{}: {Namespaces: scoped()},
+		},
+	}
+}
+
+// Leader election is scoped to the settings, so multiple controllers can run side by side
+func leaderElectionID(settingName string) string {
+	if settingName == meta.DefaultControllerName {
+		return "fefb3d10.projectcapsule.dev"
+	}
+
+	return settingName + ".fefb3d10.projectcapsule.dev"
+}
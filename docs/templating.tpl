@@ -8,6 +8,11 @@ For templating you have [Go Sprig](https://masterminds.github.io/sprig/) availab
 - `fromJson`
 - `toToml`
 - `fromToml`
+- `include` (named templates from [template libraries](#template-libraries))
+- `tpl` (renders a string as template)
+- `lookup` (reads cluster objects, see [Lookup](#lookup))
+
+Templates are rendered in a sandbox, see [Sandbox](#sandbox).
 
 ## Context
 
@@ -17,4 +22,109 @@ The follwing data context is available for templating:
 {{toYaml . }}
 ```
 
-You can access them via their Map-Path (eg. `.Config.Argo.Namespace`)
\ No newline at end of file
+You can access them via their Map-Path (eg. `.Config.Argo.Namespace`)
+
+| Key | Description |
+| --- | --- |
+| `.Tenant` | The tenant with its JSON field names (eg. `.Tenant.spec.owners`, `.Tenant.metadata.labels`) |
+| `.Tenant.Name` | Name of the tenant |
+| `.Tenant.Namespaces` | Names of the namespaces of the tenant |
+| `.Translator` | `Name`, `Labels` and `Annotations` of the translator |
+| `.Namespaces` | Namespaces of the tenant with their `Name` and `Labels` (sorted by name) |
+| `.Project` | Resolved `Name` of the appproject and the project `Group` of the tenant |
+| `.AppProject` | The current appproject of the tenant with its JSON field names, empty before it was created |
+| `.ServiceAccount` | `Name`, `Namespace` and `Username` of the ServiceAccount of the tenant |
+| `.Values` | [Values](#values) of the translator |
+| `.Config` | The configuration of the controller |
+| `.Endpoint` | The capsule-proxy endpoint of the tenant |
+
+**Deprecated**: `.Tenant.Object` exposes the tenant with its Go field names (eg. `.Tenant.Object.Spec.Owners`). Use the JSON field names on `.Tenant` instead, `.Tenant.Object` will be removed in a future release.
+
+`.AppProject` reflects the appproject before the reconcile. Templates should not derive fields from the same fields they render, as the appproject would change on every reconcile.
+
+## Values
+
+Translators can define `values`, which are available as `.Values` (similar to helm values) in the templates of the translator (project settings, applications and the custom policy). Tenants can override the keys the translator allows in `overridableValues`:
+
+```yaml
+---
+apiVersion: addons.projectcapsule.dev/v1alpha1
+kind: ArgoTranslator
+metadata:
+  name: example
+spec:
+  values:
+    environment: dev
+    resources:
+      cpu: 250m
+  overridableValues:
+    - environment
+    - resources.cpu
+  customPolicy: |
+    p, role:{{ "{{" }} .Tenant.Name {{ "}}" }}-{{ "{{" }} .Values.environment {{ "}}" }}, applications, get, */*, allow
+```
+
+The overrides of a tenant are read from the ConfigMap referenced with the [`argo.addons.projectcapsule.dev/values-from`](./annotations.md#argoaddonsprojectcapsuledevvalues-from) annotation and the [`values.argo.addons.projectcapsule.dev/<translator>`](./annotations.md#valuesargoaddonsprojectcapsuledevtranslator) annotation (which takes precedence). Keys which are not allowed by the translator are ignored.
+
+## Template Libraries
+
+Snippets which are used by many translators can be shared with a cluster-scoped `ArgoTemplateLibrary`. Each named template is rendered with `include` in the templates of the translators referencing the library in `libraries`:
+
+```yaml
+---
+apiVersion: addons.projectcapsule.dev/v1alpha1
+kind: ArgoTemplateLibrary
+metadata:
+  name: common
+spec:
+  templates:
+    - name: tenant.destinations
+      template: |
+        {{ "{{-" }} range .Tenant.Namespaces {{ "}}" }}
+        - namespace: {{ "{{" }} . {{ "}}" }}
+          server: "*"
+        {{ "{{-" }} end {{ "}}" }}
+---
+apiVersion: addons.projectcapsule.dev/v1alpha1
+kind: ArgoTranslator
+metadata:
+  name: example
+spec:
+  libraries:
+    - common
+  settings:
+    template: |
+      spec:
+        destinations:
+          {{ "{{-" }} include "tenant.destinations" . | nindent 10 {{ "}}" }}
+```
+
+Template names must be unique across the libraries referenced by a translator. Missing or invalid libraries are reported in the `TemplateLibraries` condition of the translator. Changes to a library reconcile the translators and tenants using it. Includes which (directly or indirectly) include themselves are rejected and `include`/`tpl` can be nested up to 32 levels.
+
+## Lookup
+
+With `lookup "<apiVersion>" "<kind>" "<namespace>" "<name>"` objects can be read from the cluster. It returns the object as map, an empty map if the object does not exist or a list (with `items`) when the name is empty. Only the resources and namespaces allowed in the [configuration](./config.md#lookup) can be read:
+
+```yaml
+spec:
+  sourceRepos:
+    {{ "{{-" }} range $repo := (lookup "v1" "ConfigMap" "platform" "repositories").data {{ "}}" }}
+    - {{ "{{" }} $repo {{ "}}" }}
+    {{ "{{-" }} end {{ "}}" }}
+```
+
+The tenant is reconciled, when an object it read changes (or is created).
+
+## Sandbox
+
+Functions which read the environment of the controller (`env`, `expandenv`, `getHostByName`) are not available. The same applies for functions with a different result on every call (`now`, `ago`, the `rand*` functions, `shuffle`, `uuidv4`, `bcrypt`, `htpasswd`, `encryptAES` and the `gen*` certificate functions), as the assets would be updated on every reconcile. These functions can be made available again in the [configuration](./config.md#templating).
+
+The rendering of a single template is aborted after `5s` or when the output exceeds `1MiB`, both limits can be changed in the [configuration](./config.md#templating). The functions `until`, `untilStep` and `seq` fail beyond `10000` elements and `repeat` fails beyond the maximum output size, as they allocate before any output is written.
+
+A rendering fails beyond `1000000` range iterations and template calls (including `range` over an integer, eg. `{{"{{"}} range 100000 {{"}}"}}`). A rendering which reached the timeout is stopped with its next range iteration, template call or write. A single function call (eg. `toYaml` of a large object) is not interrupted.
+
+## Caching
+
+The templates of a translator are compiled once per generation of the translator. The rendered output is kept per tenant and only rendered again, when the context of the tenant, the translator or its template libraries change. The `status`, `resourceVersion` and `managedFields` of `.Tenant` and `.AppProject` are not considered, as they change without changing the output (the namespaces of the tenant are considered). Templates reading other `status` fields are rendered with the status of the last rendering. Templates using `lookup`, `tpl` or one of the restricted functions are rendered on every reconcile, as their output may change without any change to the context.
+
+Beyond the rendered output, the controller stores a hash of all inputs of a tenant (the tenant including its state, its namespaces, the translators, template libraries, values, repositories, the proxy Service and the controller configuration) in the annotation `argo.addons.projectcapsule.dev/input-hash` on the managed objects. When the hash did not change and the last reconcile succeeded, the reconcile of the tenant is skipped. Every tenant is reconciled after the resync interval of the tenant controller (`controllers.tenant.resyncInterval`, one hour by default) regardless of the hash, which also reverts manual changes to the managed objects. The reconcile is not skipped when the Argo CD policy of the tenant is missing. Tenants using project groups, impure templates, Applications, accounts, project role tokens, project variants, repositories or remote clusters are always reconciled, as these assets are not stamped with the hash or expire.
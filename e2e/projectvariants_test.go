@@ -0,0 +1,143 @@
+// nolint
+package e2e_test
+
+import (
+	"context"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("Project Variants", func() {
+	selector := e2eLabels("e2e_project_variants")
+
+	argoaddon := &v1alpha1.ArgoAddon{}
+	originalArgoAddon := &v1alpha1.ArgoAddon{}
+
+	solar := &capsulev1beta2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "solar-variants-e2e",
+			Labels:      selector,
+			Annotations: map[string]string{},
+		},
+		Spec: capsulev1beta2.TenantSpec{
+			Owners: []capsulev1beta2.OwnerSpec{
+				{
+					Name: "alice",
+					Kind: capsulev1beta2.GroupOwner,
+				},
+			},
+		},
+	}
+
+	translator := &v1alpha1.ArgoTranslator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "test-variants",
+			Labels: selector,
+		},
+		Spec: v1alpha1.ArgoTranslatorSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					suiteLabel: "e2e_project_variants",
+				},
+			},
+			Projects: []v1alpha1.ArgocdProjectVariant{
+				{
+					Name: "prod",
+					ProjectRoles: []v1alpha1.ArgocdProjectVariantRole{
+						{
+							Name:         "deployer",
+							ClusterRoles: []string{"admin"},
+							Owner:        true,
+							Policies: []v1alpha1.ArgocdPolicyDefinition{
+								{
+									Resource: "applications",
+									Action:   []string{"*"},
+									Verb:     "allow",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	JustBeforeEach(func() {
+		// Save the current state of the argoaddon configuration
+		Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: e2eConfigName()}, originalArgoAddon)).To(Succeed())
+		argoaddon = originalArgoAddon.DeepCopy()
+
+		Expect(CleanTenants(e2eSelector("e2e_project_variants"))).ToNot(HaveOccurred())
+		Expect(CleanTranslators(e2eSelector("e2e_project_variants"))).ToNot(HaveOccurred())
+
+		Eventually(func() error {
+			translator.ResourceVersion = ""
+			return k8sClient.Create(context.TODO(), translator)
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			solar.ResourceVersion = ""
+			return k8sClient.Create(context.TODO(), solar)
+		}).Should(Succeed())
+	})
+	JustAfterEach(func() {
+		Expect(CleanTenants(e2eSelector("e2e_project_variants"))).ToNot(HaveOccurred())
+		Expect(CleanTranslators(e2eSelector("e2e_project_variants"))).ToNot(HaveOccurred())
+
+		// Restore Configuration
+		Eventually(func() error {
+			if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: originalArgoAddon.Name}, argoaddon); err != nil {
+				return err
+			}
+
+			argoaddon.Spec = originalArgoAddon.Spec
+			return k8sClient.Update(context.Background(), argoaddon)
+		}, defaultTimeoutInterval, defaultPollInterval).Should(Succeed())
+	})
+
+	It("Recreates a deleted variant appproject", func() {
+		key := client.ObjectKey{
+			Name:      argo.ProjectVariantName(solar, "prod"),
+			Namespace: argoaddon.Spec.Argo.Namespace,
+		}
+
+		var uid types.UID
+
+		By("verify the variant appproject is created", func() {
+			Eventually(func() error {
+				variant := &argocdv1alpha1.AppProject{}
+				if err := k8sClient.Get(context.Background(), key, variant); err != nil {
+					return err
+				}
+
+				uid = variant.UID
+				return nil
+			}, defaultTimeoutInterval, defaultPollInterval).Should(Succeed())
+		})
+
+		By("delete the variant appproject", func() {
+			variant := &argocdv1alpha1.AppProject{}
+			Expect(k8sClient.Get(context.Background(), key, variant)).To(Succeed())
+			Expect(k8sClient.Delete(context.Background(), variant)).To(Succeed())
+		})
+
+		By("verify the variant appproject is recreated", func() {
+			Eventually(func() bool {
+				variant := &argocdv1alpha1.AppProject{}
+				if err := k8sClient.Get(context.Background(), key, variant); err != nil {
+					return false
+				}
+
+				return variant.UID != uid && variant.DeletionTimestamp.IsZero()
+			}, defaultTimeoutInterval, defaultPollInterval).Should(BeTrue())
+		})
+	})
+})
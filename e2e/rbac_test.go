@@ -540,4 +540,77 @@ var _ = Describe("Argo RBAC Reflection", func() {
 		})
 
 	})
+
+	It("Moves Argo RBAC when the target changes", func() {
+		target := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "argocd-rbac-e2e-cm",
+				Namespace: "argocd",
+				Labels:    selector,
+			},
+		}
+
+		By("create the new rbac target", func() {
+			Expect(k8sClient.Create(context.TODO(), target)).To(Succeed())
+		})
+		defer func() {
+			Expect(k8sClient.Delete(context.TODO(), target)).To(Succeed())
+		}()
+
+		By("verify argo rbac is present in the current target", func() {
+			Eventually(func() bool {
+				configmap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{
+					Name:      argoaddon.Spec.Argo.RBACConfigMap,
+					Namespace: argoaddon.Spec.Argo.Namespace,
+				}, configmap); err != nil {
+					return false
+				}
+
+				_, ok := configmap.Data[argo.ArgoPolicyName(solar)]
+				return ok
+			}, defaultTimeoutInterval, defaultPollInterval).Should(BeTrue())
+		})
+
+		previous := argoaddon.Spec.Argo.RBACConfigMap
+
+		By("change the rbac target", func() {
+			Eventually(func() error {
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: e2eConfigName()}, argoaddon); err != nil {
+					return err
+				}
+				argoaddon.Spec.Argo.RBACConfigMap = target.Name
+				return k8sClient.Update(context.Background(), argoaddon)
+			}, defaultTimeoutInterval, defaultPollInterval).Should(Succeed())
+		})
+
+		By("verify argo rbac was removed from the previous target", func() {
+			Eventually(func() bool {
+				configmap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{
+					Name:      previous,
+					Namespace: argoaddon.Spec.Argo.Namespace,
+				}, configmap); err != nil {
+					return false
+				}
+
+				_, solarOk := configmap.Data[argo.ArgoPolicyName(solar)]
+				_, oilOk := configmap.Data[argo.ArgoPolicyName(oil)]
+				return !solarOk && !oilOk
+			}, defaultTimeoutInterval, defaultPollInterval).Should(BeTrue())
+		})
+
+		By("verify argo rbac is present in the new target", func() {
+			Eventually(func() bool {
+				configmap := &corev1.ConfigMap{}
+				if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(target), configmap); err != nil {
+					return false
+				}
+
+				_, solarOk := configmap.Data[argo.ArgoPolicyName(solar)]
+				_, oilOk := configmap.Data[argo.ArgoPolicyName(oil)]
+				return solarOk && oilOk
+			}, defaultTimeoutInterval, defaultPollInterval).Should(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,168 @@
+package argo
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+)
+
+const (
+	// Capability for accounts which may generate API tokens
+	AccountCapabilityAPIKey = "apiKey"
+
+	// Key of the token signature in the argocd-secret
+	ServerSignatureKey = "server.secretkey"
+
+	// Issuer of argocd tokens
+	TokenIssuer = "argocd"
+
+	// Share of the lifetime after which a token is rotated, so it is replaced before it expires
+	TokenRotationRatio = 0.8
+)
+
+// Token registered for an account in the argocd-secret
+type AccountToken struct {
+	ID        string `json:"id"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp,omitempty"`
+}
+
+// Name of a local account for the tenant
+func AccountName(tenant *capsulev1beta2.Tenant, name string) string {
+	return tenant.Name + "-" + name
+}
+
+// Key of the account in the argocd-cm
+func AccountKey(account string) string {
+	return "accounts." + account
+}
+
+// Key of the account tokens in the argocd-secret
+func AccountTokensKey(account string) string {
+	return AccountKey(account) + ".tokens"
+}
+
+// Parses the tokens registered for an account
+func ParseAccountTokens(raw []byte) (tokens []AccountToken, err error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	if err = json.Unmarshal(raw, &tokens); err != nil {
+		return nil, fmt.Errorf("invalid account tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+// Formats the tokens registered for an account
+func FormatAccountTokens(tokens []AccountToken) ([]byte, error) {
+	return json.Marshal(tokens)
+}
+
+// Returns the id of a signed token (the signature is not verified)
+func AccountTokenID(signed string) (string, error) {
+	claims := &jwt.RegisteredClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(signed, claims); err != nil {
+		return "", err
+	}
+
+	return claims.ID, nil
+}
+
+// Removes the tokens with the given ids and all expired tokens
+func PruneAccountTokens(tokens []AccountToken, now time.Time, ids ...string) (result []AccountToken) {
+	for _, token := range tokens {
+		if token.ExpiresAt != 0 && now.Unix() >= token.ExpiresAt {
+			continue
+		}
+
+		if slices.Contains(ids, token.ID) {
+			continue
+		}
+
+		result = append(result, token)
+	}
+
+	return
+}
+
+// Returns true if the token with the given id is registered and not expired
+func AccountTokenValid(tokens []AccountToken, id string, now time.Time) bool {
+	for _, token := range tokens {
+		if token.ID != id {
+			continue
+		}
+
+		return token.ExpiresAt == 0 || now.Unix() < token.ExpiresAt
+	}
+
+	return false
+}
+
+// Time at which a token issued and expiring at the given unix times is rotated. Tokens without expiry are
+// never rotated (zero time)
+func TokenRotation(issuedAt int64, expiresAt int64) time.Time {
+	if expiresAt == 0 {
+		return time.Time{}
+	}
+
+	lifetime := float64(expiresAt-issuedAt) * TokenRotationRatio
+
+	return time.Unix(issuedAt, 0).Add(time.Duration(lifetime) * time.Second)
+}
+
+// Rotation time of the registered token with the given id, false if the token is not registered
+func AccountTokenRotation(tokens []AccountToken, id string) (time.Time, bool) {
+	for _, token := range tokens {
+		if token.ID == id {
+			return TokenRotation(token.IssuedAt, token.ExpiresAt), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Mints a new API token for the account
+func MintAccountToken(account string, signature []byte, expiresIn time.Duration, now time.Time) (string, AccountToken, error) {
+	return MintToken(account+":"+AccountCapabilityAPIKey, signature, expiresIn, now)
+}
+
+// Mints a new token for the subject, the same way argocd does it (HS256 signed with the server signature)
+func MintToken(subject string, signature []byte, expiresIn time.Duration, now time.Time) (string, AccountToken, error) {
+	if len(signature) == 0 {
+		return "", AccountToken{}, fmt.Errorf("no server signature to sign tokens")
+	}
+
+	now = now.UTC()
+	token := AccountToken{
+		ID:       uuid.New().String(),
+		IssuedAt: now.Unix(),
+	}
+
+	claims := jwt.RegisteredClaims{
+		Issuer:    TokenIssuer,
+		Subject:   subject,
+		ID:        token.ID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	if expiresIn > 0 {
+		expires := now.Add(expiresIn)
+		claims.ExpiresAt = jwt.NewNumericDate(expires)
+		token.ExpiresAt = expires.Unix()
+	}
+
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(signature)
+	if err != nil {
+		return "", AccountToken{}, err
+	}
+
+	return signed, token, nil
+}
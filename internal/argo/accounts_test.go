@@ -0,0 +1,99 @@
+package argo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAccountKeys(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar"}}
+
+	account := AccountName(tenant, "ci")
+	assert.Equal(t, "solar-ci", account)
+	assert.Equal(t, "accounts.solar-ci", AccountKey(account))
+	assert.Equal(t, "accounts.solar-ci.tokens", AccountTokensKey(account))
+}
+
+func TestAccountTokens(t *testing.T) {
+	tokens, err := ParseAccountTokens([]byte(`[{"id":"a","iat":10},{"id":"b","iat":10,"exp":20}]`))
+	assert.NoError(t, err)
+	assert.Equal(t, []AccountToken{{ID: "a", IssuedAt: 10}, {ID: "b", IssuedAt: 10, ExpiresAt: 20}}, tokens)
+
+	raw, err := FormatAccountTokens(tokens)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[{"id":"a","iat":10},{"id":"b","iat":10,"exp":20}]`, string(raw))
+
+	assert.True(t, AccountTokenValid(tokens, "a", time.Unix(100, 0)))
+	assert.True(t, AccountTokenValid(tokens, "b", time.Unix(15, 0)))
+	assert.False(t, AccountTokenValid(tokens, "b", time.Unix(20, 0)))
+	assert.False(t, AccountTokenValid(tokens, "c", time.Unix(15, 0)))
+
+	empty, err := ParseAccountTokens(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = ParseAccountTokens([]byte("invalid"))
+	assert.Error(t, err)
+}
+
+func TestPruneAccountTokens(t *testing.T) {
+	tokens := []AccountToken{
+		{ID: "a", IssuedAt: 10},
+		{ID: "b", IssuedAt: 10, ExpiresAt: 20},
+		{ID: "c", IssuedAt: 10, ExpiresAt: 200},
+	}
+
+	assert.Equal(t, []AccountToken{{ID: "a", IssuedAt: 10}, {ID: "c", IssuedAt: 10, ExpiresAt: 200}}, PruneAccountTokens(tokens, time.Unix(100, 0)))
+	assert.Equal(t, []AccountToken{{ID: "c", IssuedAt: 10, ExpiresAt: 200}}, PruneAccountTokens(tokens, time.Unix(100, 0), "a"))
+	assert.Empty(t, PruneAccountTokens(tokens, time.Unix(300, 0), "a"))
+}
+
+func TestTokenRotation(t *testing.T) {
+	assert.True(t, TokenRotation(10, 0).IsZero())
+	assert.Equal(t, time.Unix(90, 0), TokenRotation(10, 110))
+
+	tokens := []AccountToken{{ID: "a", IssuedAt: 0, ExpiresAt: 100}}
+	rotation, ok := AccountTokenRotation(tokens, "a")
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(80, 0), rotation)
+
+	_, ok = AccountTokenRotation(tokens, "b")
+	assert.False(t, ok)
+}
+
+func TestMintAccountToken(t *testing.T) {
+	signature := []byte("secret")
+	now := time.Now()
+
+	signed, token, err := MintAccountToken("solar-ci", signature, time.Hour, now)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token.ID)
+	assert.Equal(t, now.Unix(), token.IssuedAt)
+	assert.Equal(t, now.Add(time.Hour).Unix(), token.ExpiresAt)
+
+	claims := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (interface{}, error) { return signature, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "solar-ci:apiKey", claims.Subject)
+	assert.Equal(t, "argocd", claims.Issuer)
+	assert.Equal(t, token.ID, claims.ID)
+
+	id, err := AccountTokenID(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, id)
+
+	_, err = AccountTokenID("invalid")
+	assert.Error(t, err)
+
+	_, token, err = MintAccountToken("solar-ci", signature, 0, now)
+	assert.NoError(t, err)
+	assert.Zero(t, token.ExpiresAt)
+
+	_, _, err = MintAccountToken("solar-ci", nil, 0, now)
+	assert.Error(t, err)
+}
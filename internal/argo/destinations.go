@@ -28,3 +28,35 @@ func RemoveProjectDestination(appProject *argocdv1alpha1.AppProject, dest argocd
 	}
 	appProject.Spec.Destinations = newDestinations
 }
+
+// Replaces the previously managed destinations (by name) with the desired destinations. Destinations which were
+// not managed are kept as they are. Returns the names of the destinations which are now managed.
+func ReplaceManagedDestinations(
+	appProject *argocdv1alpha1.AppProject,
+	previous []string,
+	desired []argocdv1alpha1.ApplicationDestination,
+) (managed []string) {
+	owned := make(map[string]struct{}, len(previous)+len(desired))
+	for _, name := range previous {
+		owned[name] = struct{}{}
+	}
+	for _, dest := range desired {
+		owned[dest.Name] = struct{}{}
+	}
+
+	destinations := []argocdv1alpha1.ApplicationDestination{}
+	for _, dest := range appProject.Spec.Destinations {
+		if _, ok := owned[dest.Name]; !ok {
+			destinations = append(destinations, dest)
+		}
+	}
+
+	for _, dest := range desired {
+		destinations = append(destinations, dest)
+		managed = append(managed, dest.Name)
+	}
+
+	appProject.Spec.Destinations = destinations
+
+	return
+}
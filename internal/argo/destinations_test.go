@@ -159,3 +159,31 @@ func TestRemoveProjectDestination(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceManagedDestinations(t *testing.T) {
+	appProject := &argocdv1alpha1.AppProject{
+		Spec: argocdv1alpha1.AppProjectSpec{
+			Destinations: []argocdv1alpha1.ApplicationDestination{
+				{Name: "solar", Namespace: "*", Server: "https://proxy"},
+				{Name: "solar-east", Namespace: "*", Server: "https://east-old"},
+				{Name: "solar-west", Namespace: "*", Server: "https://west"},
+			},
+		},
+	}
+
+	managed := ReplaceManagedDestinations(appProject, []string{"solar-east", "solar-west"}, []argocdv1alpha1.ApplicationDestination{
+		{Name: "solar-east", Namespace: "*", Server: "https://east"},
+	})
+
+	assert.Equal(t, []string{"solar-east"}, managed)
+	assert.Equal(t, []argocdv1alpha1.ApplicationDestination{
+		{Name: "solar", Namespace: "*", Server: "https://proxy"},
+		{Name: "solar-east", Namespace: "*", Server: "https://east"},
+	}, appProject.Spec.Destinations)
+
+	managed = ReplaceManagedDestinations(appProject, managed, nil)
+	assert.Empty(t, managed)
+	assert.Equal(t, []argocdv1alpha1.ApplicationDestination{
+		{Name: "solar", Namespace: "*", Server: "https://proxy"},
+	}, appProject.Spec.Destinations)
+}
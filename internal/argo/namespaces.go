@@ -0,0 +1,78 @@
+package argo
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	// Argo Command Parameter for the namespaces the application controller and server watch
+	CmdParamApplicationNamespaces = "application.namespaces"
+
+	// Argo Command Parameter for the namespaces the applicationset controller watches
+	CmdParamApplicationSetNamespaces = "applicationsetcontroller.namespaces"
+)
+
+// Parses a comma separated namespace parameter from the argocd-cmd-params-cm
+func ParseNamespaceParam(value string) (namespaces []string) {
+	for _, ns := range strings.Split(value, ",") {
+		if ns = strings.TrimSpace(ns); ns != "" {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	return
+}
+
+// Formats namespaces as comma separated namespace parameter (sorted and unique)
+func FormatNamespaceParam(namespaces []string) string {
+	unique := make(map[string]struct{}, len(namespaces))
+	result := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		if _, exists := unique[ns]; exists {
+			continue
+		}
+
+		unique[ns] = struct{}{}
+		result = append(result, ns)
+	}
+
+	sort.Strings(result)
+
+	return strings.Join(result, ",")
+}
+
+// Replaces the previously managed entries with the desired entries. Entries which were not
+// managed are kept as they are. Returns the resulting entries and the entries which are now managed.
+func ReplaceManaged(current []string, previous []string, desired []string) (result []string, managed []string) {
+	owned := make(map[string]struct{}, len(previous))
+	for _, entry := range previous {
+		owned[entry] = struct{}{}
+	}
+
+	present := make(map[string]struct{}, len(current))
+	for _, entry := range current {
+		if _, ok := owned[entry]; ok {
+			continue
+		}
+
+		if _, ok := present[entry]; ok {
+			continue
+		}
+
+		present[entry] = struct{}{}
+		result = append(result, entry)
+	}
+
+	for _, entry := range desired {
+		if _, ok := present[entry]; ok {
+			continue
+		}
+
+		present[entry] = struct{}{}
+		result = append(result, entry)
+		managed = append(managed, entry)
+	}
+
+	return
+}
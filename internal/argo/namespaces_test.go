@@ -0,0 +1,65 @@
+package argo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNamespaceParam(t *testing.T) {
+	assert.Equal(t, []string{"argocd", "solar-*", "wind"}, ParseNamespaceParam("argocd, solar-*,,wind "))
+	assert.Empty(t, ParseNamespaceParam(""))
+}
+
+func TestFormatNamespaceParam(t *testing.T) {
+	assert.Equal(t, "argocd,solar-*,wind", FormatNamespaceParam([]string{"wind", "argocd", "solar-*", "wind"}))
+	assert.Equal(t, "", FormatNamespaceParam(nil))
+}
+
+func TestReplaceManaged(t *testing.T) {
+	tests := []struct {
+		testName        string
+		current         []string
+		previous        []string
+		desired         []string
+		expectedResult  []string
+		expectedManaged []string
+	}{
+		{
+			testName:        "Adds desired entries",
+			current:         []string{"argocd"},
+			desired:         []string{"solar-prod", "solar-dev"},
+			expectedResult:  []string{"argocd", "solar-prod", "solar-dev"},
+			expectedManaged: []string{"solar-prod", "solar-dev"},
+		},
+		{
+			testName:        "Removes no longer desired entries",
+			current:         []string{"argocd", "solar-prod", "solar-dev"},
+			previous:        []string{"solar-prod", "solar-dev"},
+			desired:         []string{"solar-prod"},
+			expectedResult:  []string{"argocd", "solar-prod"},
+			expectedManaged: []string{"solar-prod"},
+		},
+		{
+			testName:        "Does not take ownership of unmanaged entries",
+			current:         []string{"solar-prod"},
+			desired:         []string{"solar-prod", "solar-dev"},
+			expectedResult:  []string{"solar-prod", "solar-dev"},
+			expectedManaged: []string{"solar-dev"},
+		},
+		{
+			testName:       "Removes all managed entries",
+			current:        []string{"argocd", "solar-prod"},
+			previous:       []string{"solar-prod"},
+			expectedResult: []string{"argocd"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			result, managed := ReplaceManaged(tt.current, tt.previous, tt.desired)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedManaged, managed)
+		})
+	}
+}
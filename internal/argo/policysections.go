@@ -0,0 +1,97 @@
+package argo
+
+import (
+	"strings"
+
+	addonsv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+)
+
+const (
+	// Markers for the managed sections in a policy
+	PolicySectionBegin = "# BEGIN capsule-argo-addon "
+	PolicySectionEnd   = "# END capsule-argo-addon "
+)
+
+// Replaces the managed section in the policy with the given content. The section is appended
+// if it does not exist yet and removed if the content is empty.
+func ReplacePolicySection(policy string, section string, content string) string {
+	begin := PolicySectionBegin + section
+	end := PolicySectionEnd + section
+
+	var result []string
+	inSection := false
+	for _, line := range strings.Split(policy, "\n") {
+		switch {
+		case strings.TrimSpace(line) == begin:
+			inSection = true
+		case strings.TrimSpace(line) == end:
+			inSection = false
+		case !inSection:
+			result = append(result, line)
+		}
+	}
+
+	// Remove trailing empty lines
+	for len(result) > 0 && strings.TrimSpace(result[len(result)-1]) == "" {
+		result = result[:len(result)-1]
+	}
+
+	content = strings.TrimSpace(content)
+	if content != "" {
+		result = append(result, begin, content, end)
+	}
+
+	if len(result) == 0 {
+		return ""
+	}
+
+	return strings.Join(result, "\n") + "\n"
+}
+
+// Returns the content of the managed section in the policy
+func GetPolicySection(policy string, section string) string {
+	begin := PolicySectionBegin + section
+	end := PolicySectionEnd + section
+
+	var result []string
+	inSection := false
+	for _, line := range strings.Split(policy, "\n") {
+		switch {
+		case strings.TrimSpace(line) == begin:
+			inSection = true
+		case strings.TrimSpace(line) == end:
+			inSection = false
+		case inSection:
+			result = append(result, line)
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// Location the policies of a tenant are written to
+type PolicyTarget struct {
+	Kind      addonsv1alpha1.RBACTargetKind
+	Namespace string
+	Name      string
+}
+
+// Reference of the target as recorded on the tenant (<kind>/<namespace>/<name>)
+func (t PolicyTarget) String() string {
+	return string(t.Kind) + "/" + t.Namespace + "/" + t.Name
+}
+
+// Parses the reference of a target, false if the reference is invalid
+func ParsePolicyTarget(reference string) (PolicyTarget, bool) {
+	parts := strings.SplitN(reference, "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return PolicyTarget{}, false
+	}
+
+	switch kind := addonsv1alpha1.RBACTargetKind(parts[0]); kind {
+	case addonsv1alpha1.RBACTargetConfigMap, addonsv1alpha1.RBACTargetArgoCD:
+		return PolicyTarget{Kind: kind, Namespace: parts[1], Name: parts[2]}, true
+	}
+
+	return PolicyTarget{}, false
+}
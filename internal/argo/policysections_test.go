@@ -0,0 +1,54 @@
+package argo
+
+import (
+	"testing"
+
+	addonsv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplacePolicySection(t *testing.T) {
+	policy := "g, admins, role:admin\n"
+
+	// Append a new section
+	policy = ReplacePolicySection(policy, "solar", "p, role:solar,applications,get,solar/*,allow\n")
+	assert.Equal(t, "g, admins, role:admin\n"+
+		"# BEGIN capsule-argo-addon solar\n"+
+		"p, role:solar,applications,get,solar/*,allow\n"+
+		"# END capsule-argo-addon solar\n", policy)
+
+	// Append another section
+	policy = ReplacePolicySection(policy, "wind", "p, role:wind,applications,get,wind/*,allow")
+	assert.Equal(t, "p, role:wind,applications,get,wind/*,allow", GetPolicySection(policy, "wind"))
+
+	// Replace an existing section
+	policy = ReplacePolicySection(policy, "solar", "p, role:solar,applications,*,solar/*,allow")
+	assert.Equal(t, "p, role:solar,applications,*,solar/*,allow", GetPolicySection(policy, "solar"))
+	assert.Equal(t, "p, role:wind,applications,get,wind/*,allow", GetPolicySection(policy, "wind"))
+
+	// Remove the sections
+	policy = ReplacePolicySection(policy, "solar", "")
+	assert.Empty(t, GetPolicySection(policy, "solar"))
+	policy = ReplacePolicySection(policy, "wind", "")
+	assert.Equal(t, "g, admins, role:admin\n", policy)
+
+	assert.Equal(t, "", ReplacePolicySection("", "solar", ""))
+}
+
+func TestPolicyTarget(t *testing.T) {
+	target := PolicyTarget{Kind: addonsv1alpha1.RBACTargetConfigMap, Namespace: "argocd", Name: "argocd-rbac-cm"}
+	assert.Equal(t, "ConfigMap/argocd/argocd-rbac-cm", target.String())
+
+	parsed, ok := ParsePolicyTarget(target.String())
+	assert.True(t, ok)
+	assert.Equal(t, target, parsed)
+
+	parsed, ok = ParsePolicyTarget("ArgoCD/openshift-gitops/openshift-gitops")
+	assert.True(t, ok)
+	assert.NotEqual(t, target, parsed, "a changed target is detected")
+
+	for _, reference := range []string{"", "ConfigMap/argocd", "Secret/argocd/argocd-secret", "ConfigMap//argocd-rbac-cm"} {
+		_, ok = ParsePolicyTarget(reference)
+		assert.False(t, ok, reference)
+	}
+}
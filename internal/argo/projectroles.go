@@ -0,0 +1,76 @@
+package argo
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	addonsv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+)
+
+// Name of a project role as used in policies and token subjects
+func ProjectRoleName(project string, role string) string {
+	return "proj:" + project + ":" + role
+}
+
+// Converts the ArgoCD Project Policy Definitions to the policies of a project role
+func ProjectRolePolicies(project string, role string, policies []addonsv1alpha1.ArgocdPolicyDefinition) (result []string) {
+	for _, pol := range policies {
+		for _, line := range strings.Split(PolicyString(ProjectRoleName(project, role), project, pol), "\n") {
+			if line != "" {
+				result = append(result, line)
+			}
+		}
+	}
+
+	return
+}
+
+// Mints a new token for the project role
+func MintProjectRoleToken(
+	project string,
+	role string,
+	signature []byte,
+	expiresIn time.Duration,
+	now time.Time,
+) (string, argocdv1alpha1.JWTToken, error) {
+	signed, token, err := MintToken(ProjectRoleName(project, role), signature, expiresIn, now)
+	if err != nil {
+		return "", argocdv1alpha1.JWTToken{}, err
+	}
+
+	return signed, argocdv1alpha1.JWTToken{
+		ID:        token.ID,
+		IssuedAt:  token.IssuedAt,
+		ExpiresAt: token.ExpiresAt,
+	}, nil
+}
+
+// Returns the time the token with the given id is due for rotation, false if the token is not registered on the role
+func ProjectRoleTokenRotation(tokens []argocdv1alpha1.JWTToken, id string) (time.Time, bool) {
+	for _, token := range tokens {
+		if token.ID == id {
+			return TokenRotation(token.IssuedAt, token.ExpiresAt), true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// Removes the tokens with the given ids and all expired tokens from the role
+func PruneProjectRoleTokens(tokens []argocdv1alpha1.JWTToken, now time.Time, ids ...string) (result []argocdv1alpha1.JWTToken) {
+	for _, token := range tokens {
+		if token.ExpiresAt != 0 && now.Unix() >= token.ExpiresAt {
+			continue
+		}
+
+		if token.ID != "" && slices.Contains(ids, token.ID) {
+			continue
+		}
+
+		result = append(result, token)
+	}
+
+	return
+}
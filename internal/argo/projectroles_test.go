@@ -0,0 +1,68 @@
+package argo
+
+import (
+	"testing"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/golang-jwt/jwt/v4"
+	addonsv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectRolePolicies(t *testing.T) {
+	assert.Equal(t, "proj:solar:ci", ProjectRoleName("solar", "ci"))
+
+	policies := ProjectRolePolicies("solar", "ci", []addonsv1alpha1.ArgocdPolicyDefinition{
+		{
+			Resource: "applications",
+			Action:   []string{"get", "sync"},
+			Verb:     "allow",
+			Path:     "*",
+		},
+	})
+
+	assert.Equal(t, []string{
+		"p, proj:solar:ci,applications,get,solar/*,allow",
+		"p, proj:solar:ci,applications,sync,solar/*,allow",
+	}, policies)
+}
+
+func TestProjectRoleTokens(t *testing.T) {
+	signature := []byte("secret")
+	now := time.Now()
+
+	signed, token, err := MintProjectRoleToken("solar", "ci", signature, time.Hour, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Unix(), token.IssuedAt)
+	assert.Equal(t, now.Add(time.Hour).Unix(), token.ExpiresAt)
+
+	claims := &jwt.RegisteredClaims{}
+	_, err = jwt.ParseWithClaims(signed, claims, func(*jwt.Token) (interface{}, error) { return signature, nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "proj:solar:ci", claims.Subject)
+	assert.Equal(t, token.ID, claims.ID)
+
+	tokens := []argocdv1alpha1.JWTToken{
+		token,
+		{ID: "expired", IssuedAt: 10, ExpiresAt: 20},
+		{IssuedAt: 10},
+	}
+
+	rotation, ok := ProjectRoleTokenRotation(tokens, token.ID)
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(token.IssuedAt, 0).Add(48*time.Minute), rotation)
+
+	rotation, ok = ProjectRoleTokenRotation(tokens, "expired")
+	assert.True(t, ok)
+	assert.Equal(t, time.Unix(18, 0), rotation)
+
+	_, ok = ProjectRoleTokenRotation(tokens, "unknown")
+	assert.False(t, ok)
+
+	assert.Equal(t, []argocdv1alpha1.JWTToken{token, {IssuedAt: 10}}, PruneProjectRoleTokens(tokens, now))
+	assert.Equal(t, []argocdv1alpha1.JWTToken{{IssuedAt: 10}}, PruneProjectRoleTokens(tokens, now, token.ID, ""))
+
+	_, _, err = MintProjectRoleToken("solar", "ci", nil, 0, now)
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/argoproj/argo-cd/v2/util/rbac"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
 	v1 "k8s.io/api/rbac/v1"
 
@@ -53,17 +54,27 @@ func BindingString(subject v1.Subject, role string) string {
 
 // Adds Default Policies (So Users can have basic interractions with the project)
 func DefaultPolicies(tenant *capsulev1beta2.Tenant, clusterPermission bool) (result []string) {
+	return ProjectDefaultPolicies(
+		meta.TenantProjectName(tenant),
+		DefaultPolicyReadOnly(tenant),
+		DefaultPolicyOwner(tenant),
+		clusterPermission,
+	)
+}
+
+// Adds Default Policies for an appproject with the given read-only and owner roles
+func ProjectDefaultPolicies(project string, readOnly string, owner string, clusterPermission bool) (result []string) {
 	// Read-Only Policy
-	result = append(result, PolicyString(DefaultPolicyReadOnly(tenant),
-		tenant.Name,
+	result = append(result, PolicyString(readOnly,
+		project,
 		addonsv1alpha1.ArgocdPolicyDefinition{
 			Resource: "projects",
 			Action:   []string{"get"},
 			Verb:     "allow",
 		}))
 
-	result = append(result, PolicyString(DefaultPolicyOwner(tenant),
-		tenant.Name,
+	result = append(result, PolicyString(owner,
+		project,
 		addonsv1alpha1.ArgocdPolicyDefinition{
 			Resource: "projects",
 			Action:   []string{"update"},
@@ -71,16 +82,16 @@ func DefaultPolicies(tenant *capsulev1beta2.Tenant, clusterPermission bool) (res
 		}))
 
 	if clusterPermission {
-		result = append(result, PolicyString(DefaultPolicyReadOnly(tenant),
-			tenant.Name,
+		result = append(result, PolicyString(readOnly,
+			project,
 			addonsv1alpha1.ArgocdPolicyDefinition{
 				Resource: "clusters",
 				Action:   []string{"get"},
 				Verb:     "allow",
 				Path:     "*",
 			}))
-		result = append(result, PolicyString(DefaultPolicyOwner(tenant),
-			tenant.Name,
+		result = append(result, PolicyString(owner,
+			project,
 			addonsv1alpha1.ArgocdPolicyDefinition{
 				Resource: "clusters",
 				Action:   []string{"update"},
@@ -102,6 +113,16 @@ func DefaultPolicyReadOnly(tenant *capsulev1beta2.Tenant) string {
 	return fmt.Sprintf("caa:role:%s:read-only", tenant.Name)
 }
 
+// Default Policy for Owners of a project variant
+func VariantPolicyOwner(tenant *capsulev1beta2.Tenant, variant string) string {
+	return fmt.Sprintf("caa:role:%s:%s:owner", tenant.Name, variant)
+}
+
+// Default Policy for Read-Only of a project variant
+func VariantPolicyReadOnly(tenant *capsulev1beta2.Tenant, variant string) string {
+	return fmt.Sprintf("caa:role:%s:%s:read-only", tenant.Name, variant)
+}
+
 // Default Policy for Tenant Read-Only
 func TenantPolicy(tenant *capsulev1beta2.Tenant, policyName string) string {
 	return fmt.Sprintf("role:%s:%s", tenant.Name, policyName)
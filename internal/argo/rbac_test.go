@@ -77,3 +77,24 @@ func TestDefaultPoliciesNoProxy(t *testing.T) {
 	result := DefaultPolicies(tenant, false)
 	assert.Equal(t, expectedResult, result, "DefaultPolicies should return correct default policies")
 }
+
+func TestProjectDefaultPolicies(t *testing.T) {
+	expectedResult := []string{
+		"p, caa:role:test-tenant:prod:read-only,projects,get,test-tenant-prod,allow\n",
+		"p, caa:role:test-tenant:prod:owner,projects,update,test-tenant-prod,allow\n",
+	}
+
+	tenant := &capsulev1beta2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test-tenant",
+		},
+	}
+
+	result := ProjectDefaultPolicies(
+		ProjectVariantName(tenant, "prod"),
+		VariantPolicyReadOnly(tenant, "prod"),
+		VariantPolicyOwner(tenant, "prod"),
+		false,
+	)
+	assert.Equal(t, expectedResult, result, "ProjectDefaultPolicies should return correct default policies")
+}
@@ -0,0 +1,51 @@
+package argo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+const (
+	// Label argo uses to identify secrets
+	SecretTypeLabel = "argocd.argoproj.io/secret-type"
+
+	// Secret type for clusters
+	SecretTypeCluster = "cluster"
+
+	// Secret type for repositories
+	SecretTypeRepository = "repository"
+
+	// Secret type for repository credential templates
+	SecretTypeRepoCreds = "repo-creds"
+)
+
+// Verify if the given secret type is a repository secret type
+func IsRepositorySecretType(secretType string) bool {
+	return secretType == SecretTypeRepository || secretType == SecretTypeRepoCreds
+}
+
+// Name of the reflected repository secret in the argo namespace
+func RepositorySecretName(namespace string, name string) string {
+	hash := sha256.Sum256([]byte(namespace + "/" + name))
+	suffix := "-" + hex.EncodeToString(hash[:])[:8]
+
+	prefix := namespace + "-" + name
+	if maxLen := 253 - len(suffix); len(prefix) > maxLen {
+		prefix = strings.TrimSuffix(prefix[:maxLen], "-")
+	}
+
+	return prefix + suffix
+}
+
+// Verify if the repository url is permitted by the source repositories of the project. Credential templates
+// are matched as url prefix, therefor everything below the url must be permitted.
+func RepositoryPermitted(appProject *argocdv1alpha1.AppProject, secretType string, url string) bool {
+	if secretType == SecretTypeRepoCreds {
+		url = strings.TrimSuffix(url, "/") + "/*"
+	}
+
+	return appProject.IsSourcePermitted(argocdv1alpha1.ApplicationSource{RepoURL: url})
+}
@@ -0,0 +1,49 @@
+package argo
+
+import (
+	"strings"
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositorySecretName(t *testing.T) {
+	name := RepositorySecretName("solar-prod", "git")
+	assert.True(t, strings.HasPrefix(name, "solar-prod-git-"), "Expected readable prefix")
+	assert.Equal(t, name, RepositorySecretName("solar-prod", "git"), "Expected stable name")
+	assert.NotEqual(t, RepositorySecretName("solar-prod", "git"), RepositorySecretName("solar", "prod-git"), "Expected unique names")
+
+	long := RepositorySecretName(strings.Repeat("a", 63), strings.Repeat("b", 253))
+	assert.LessOrEqual(t, len(long), 253, "Expected name to be truncated")
+}
+
+func TestRepositoryPermitted(t *testing.T) {
+	appProject := &argocdv1alpha1.AppProject{
+		Spec: argocdv1alpha1.AppProjectSpec{
+			SourceRepos: []string{
+				"https://github.com/solar/*",
+				"https://gitlab.com/wind/app.git",
+			},
+		},
+	}
+
+	tests := []struct {
+		secretType string
+		url        string
+		expected   bool
+		testName   string
+	}{
+		{SecretTypeRepository, "https://github.com/solar/app.git", true, "Repository matching glob"},
+		{SecretTypeRepository, "https://gitlab.com/wind/app.git", true, "Repository matching exactly"},
+		{SecretTypeRepository, "https://github.com/wind/app.git", false, "Repository not permitted"},
+		{SecretTypeRepoCreds, "https://github.com/solar", true, "Credentials prefix permitted"},
+		{SecretTypeRepoCreds, "https://gitlab.com/wind", false, "Credentials prefix broader than permitted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.testName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RepositoryPermitted(appProject, tt.secretType, tt.url))
+		})
+	}
+}
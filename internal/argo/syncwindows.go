@@ -0,0 +1,91 @@
+package argo
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+)
+
+// Sync window which denies all syncs to the given namespaces
+func DenySyncWindow(namespaces []string) *argocdv1alpha1.SyncWindow {
+	if len(namespaces) == 0 {
+		return nil
+	}
+
+	sorted := append([]string{}, namespaces...)
+	sort.Strings(sorted)
+
+	return &argocdv1alpha1.SyncWindow{
+		Kind:       "deny",
+		Schedule:   "* * * * *",
+		Duration:   "24h",
+		Namespaces: sorted,
+		TimeZone:   "UTC",
+	}
+}
+
+// Parses a sync window (json), returns nil for an empty value
+func ParseSyncWindow(raw string) (*argocdv1alpha1.SyncWindow, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	window := &argocdv1alpha1.SyncWindow{}
+	if err := json.Unmarshal([]byte(raw), window); err != nil {
+		return nil, err
+	}
+
+	return window, nil
+}
+
+// Formats a sync window (json), returns an empty value for nil
+func FormatSyncWindow(window *argocdv1alpha1.SyncWindow) (string, error) {
+	if window == nil {
+		return "", nil
+	}
+
+	raw, err := json.Marshal(window)
+
+	return string(raw), err
+}
+
+// Replaces the previously injected sync window with the desired sync window. Returns the injected sync window, which is
+// nil if the desired window already existed without being injected.
+func ReplaceSyncWindow(
+	appProject *argocdv1alpha1.AppProject,
+	previous *argocdv1alpha1.SyncWindow,
+	desired *argocdv1alpha1.SyncWindow,
+) *argocdv1alpha1.SyncWindow {
+	windows := argocdv1alpha1.SyncWindows{}
+	removed := false
+	for _, window := range appProject.Spec.SyncWindows {
+		// Only remove the window once, an equal window may have been added by someone else
+		if previous != nil && !removed && reflect.DeepEqual(*window, *previous) {
+			removed = true
+
+			continue
+		}
+
+		windows = append(windows, window)
+	}
+
+	if desired == nil {
+		appProject.Spec.SyncWindows = windows
+
+		return nil
+	}
+
+	for _, window := range windows {
+		if reflect.DeepEqual(*window, *desired) {
+			appProject.Spec.SyncWindows = windows
+
+			return nil
+		}
+	}
+
+	appProject.Spec.SyncWindows = append(windows, desired)
+
+	return desired
+}
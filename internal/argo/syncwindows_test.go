@@ -0,0 +1,65 @@
+package argo
+
+import (
+	"testing"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDenySyncWindow(t *testing.T) {
+	assert.Nil(t, DenySyncWindow(nil))
+
+	window := DenySyncWindow([]string{"solar-prod", "solar-dev"})
+	assert.Equal(t, "deny", window.Kind)
+	assert.Equal(t, []string{"solar-dev", "solar-prod"}, window.Namespaces)
+}
+
+func TestFormatSyncWindow(t *testing.T) {
+	raw, err := FormatSyncWindow(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", raw)
+
+	window := DenySyncWindow([]string{"solar-prod"})
+	raw, err = FormatSyncWindow(window)
+	assert.NoError(t, err)
+
+	parsed, err := ParseSyncWindow(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, window, parsed)
+
+	parsed, err = ParseSyncWindow("")
+	assert.NoError(t, err)
+	assert.Nil(t, parsed)
+}
+
+func TestReplaceSyncWindow(t *testing.T) {
+	custom := &argocdv1alpha1.SyncWindow{Kind: "allow", Schedule: "0 8 * * *", Duration: "1h"}
+	appProject := &argocdv1alpha1.AppProject{
+		Spec: argocdv1alpha1.AppProjectSpec{
+			SyncWindows: argocdv1alpha1.SyncWindows{custom},
+		},
+	}
+
+	// Inject
+	window := DenySyncWindow([]string{"solar-prod"})
+	injected := ReplaceSyncWindow(appProject, nil, window)
+	assert.Equal(t, window, injected)
+	assert.Len(t, appProject.Spec.SyncWindows, 2)
+
+	// Update
+	updated := DenySyncWindow([]string{"solar-prod", "solar-dev"})
+	injected = ReplaceSyncWindow(appProject, injected, updated)
+	assert.Equal(t, updated, injected)
+	assert.Equal(t, argocdv1alpha1.SyncWindows{custom, updated}, appProject.Spec.SyncWindows)
+
+	// Remove
+	injected = ReplaceSyncWindow(appProject, injected, nil)
+	assert.Nil(t, injected)
+	assert.Equal(t, argocdv1alpha1.SyncWindows{custom}, appProject.Spec.SyncWindows)
+
+	// Existing equal windows are not injected
+	injected = ReplaceSyncWindow(appProject, nil, custom)
+	assert.Nil(t, injected)
+	assert.Len(t, appProject.Spec.SyncWindows, 1)
+}
@@ -6,5 +6,20 @@ import (
 )
 
 func ArgoPolicyName(tenant *capsulev1beta2.Tenant) string {
-	return "policy." + meta.TenantProjectName(tenant) + ".csv"
+	return ProjectPolicyName(meta.TenantProjectName(tenant))
+}
+
+// Name of the policy (rbac configmap key) for an appproject
+func ProjectPolicyName(project string) string {
+	return "policy." + project + ".csv"
+}
+
+// Name of the appproject of a project variant of the tenant
+func ProjectVariantName(tenant *capsulev1beta2.Tenant, variant string) string {
+	return meta.TenantProjectName(tenant) + "-" + variant
+}
+
+// Name of the argo cluster (and destination) of the tenant on a remote cluster
+func RemoteClusterName(tenant *capsulev1beta2.Tenant, cluster string) string {
+	return tenant.Name + "-" + cluster
 }
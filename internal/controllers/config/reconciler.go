@@ -19,7 +19,10 @@ package config
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"slices"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -30,7 +33,10 @@ import (
 
 	"github.com/go-logr/logr"
 	addonsv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	"github.com/peak-scale/capsule-argo-addon/internal/stores"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
 )
 
 // CapsuleArgocdReconciler reconciles a CapsuleArgocd object
@@ -45,6 +51,12 @@ type ConfigReconciler struct {
 
 type ReconcilerConfig struct {
 	SettingName string
+
+	// Namespaces the manager cache was scoped to on startup
+	CacheNamespaces []string
+
+	// Options the controllers were set up with on startup
+	Controllers *addonsv1alpha1.ControllersConfig
 }
 
 //nolint:lll
@@ -128,14 +140,55 @@ func (r *ConfigReconciler) reconcile(
 
 	// Validate the Settings
 	if err := r.validateSettings(ctx, client, &origin.Spec); err != nil {
+		if r.Recorder != nil {
+			r.Recorder.Event(origin, corev1.EventTypeWarning, "InvalidSettings",
+				fmt.Sprintf("The settings were not applied: %v", err))
+		}
+
 		return fmt.Errorf("failed to validate settings: %v", err)
 	}
 
 	log.V(5).Info("Validated settings", "settings", origin.Spec)
 
+	// The cache is scoped on startup, changed namespaces are only cached after a restart
+	if r.Config.CacheNamespaces != nil && !slices.Equal(r.Config.CacheNamespaces, origin.Spec.CacheNamespaces()) {
+		log.Info("cached namespaces changed, restart the controller to apply them",
+			"cached", r.Config.CacheNamespaces, "desired", origin.Spec.CacheNamespaces())
+
+		if r.Recorder != nil {
+			r.Recorder.Event(origin, corev1.EventTypeWarning, "RestartRequired",
+				"The namespaces of the settings changed, restart the controller to cache them")
+		}
+	}
+
+	// The controllers are set up on startup, changed options are only applied after a restart
+	if r.Config.Controllers != nil && !reflect.DeepEqual(*r.Config.Controllers, origin.Spec.Controllers) {
+		log.Info("controller options changed, restart the controller to apply them")
+
+		if r.Recorder != nil {
+			r.Recorder.Event(origin, corev1.EventTypeWarning, "RestartRequired",
+				"The controller options of the settings changed, restart the controller to apply them")
+		}
+	}
+
 	// Update the store with the new configuration
 	r.Store.Update(&origin.Spec)
 
+	// Apply the templating limits and functions for all following renderings
+	sandbox.Configure(sandbox.Settings{
+		Functions:     origin.Spec.Templating.Functions,
+		Timeout:       origin.Spec.Templating.Timeout.Duration,
+		MaxOutputSize: origin.Spec.Templating.MaxOutputSize,
+	})
+
+	// Surface the options the controllers are running with
+	if r.Config.Controllers != nil && !reflect.DeepEqual(origin.Status.Controllers, r.Config.Controllers) {
+		origin.Status.Controllers = r.Config.Controllers.DeepCopy()
+		if err := client.Status().Update(ctx, origin); err != nil {
+			return fmt.Errorf("failed to update controller status: %w", err)
+		}
+	}
+
 	// Update the status with the new configuration
 	//origin.Status.Config = origin.Spec.DeepCopy().Config
 	//if err := client.Status().Update(ctx, origin); err != nil {
@@ -150,7 +203,48 @@ func (r *ConfigReconciler) reconcile(
 
 // Validates configuration before it's applied as status and transferred to the store
 // If validation fails, the configuration is not applied
-func (r *ConfigReconciler) validateSettings(_ context.Context, _ client.Client, _ *addonsv1alpha1.ArgoAddonSpec) error {
+func (r *ConfigReconciler) validateSettings(ctx context.Context, c client.Client, spec *addonsv1alpha1.ArgoAddonSpec) error {
 	//r.Store.Update(origin)
+	if err := sandbox.Validate(spec.Templating.Functions); err != nil {
+		return fmt.Errorf("invalid templating functions: %w", err)
+	}
+
+	if err := validateInstances(ctx, c, spec); err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// Refuses the removal of argocd instances which still manage tenants. Their assets would be orphaned in the
+// namespace of the removed instance, tenants must be moved to another instance before the instance is removed
+func validateInstances(ctx context.Context, c client.Client, spec *addonsv1alpha1.ArgoAddonSpec) error {
+	tenants := &capsulev1beta2.TenantList{}
+	if err := c.List(ctx, tenants); err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	used := map[string][]string{}
+	for _, tenant := range tenants.Items {
+		instance := meta.TenantManagedInstance(&tenant)
+		if instance == "" {
+			continue
+		}
+
+		if _, err := spec.GetInstance(instance); err != nil {
+			used[instance] = append(used[instance], tenant.Name)
+		}
+	}
+
+	if len(used) == 0 {
+		return nil
+	}
+
+	instances := make([]string, 0, len(used))
+	for instance, names := range used {
+		instances = append(instances, fmt.Sprintf("%s (tenants %v)", instance, names))
+	}
+	slices.Sort(instances)
+
+	return fmt.Errorf("removed argocd instances are still used, move the tenants to another instance first: %v", instances)
+}
@@ -2,27 +2,39 @@ package tenant
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
 
 	argocdapi "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/go-logr/logr"
 	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
 	configv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
-	"github.com/peak-scale/capsule-argo-addon/internal/argo"
 	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	"github.com/peak-scale/capsule-argo-addon/internal/stores"
+	rendercache "github.com/peak-scale/capsule-argo-addon/internal/template/cache"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -34,16 +46,72 @@ var _ reconcile.Reconciler = &TenancyController{}
 
 type TenancyController struct {
 	client.Client
-	Metrics  *metrics.Recorder
-	Scheme   *runtime.Scheme
-	Recorder record.EventRecorder
-	Log      logr.Logger
-	Settings *stores.ConfigStore
-	requeue  chan event.GenericEvent
+	// Reader bypassing the cache, for objects which are not cached (eg. kubeconfig secrets)
+	APIReader client.Reader
+	Metrics   *metrics.Recorder
+	Scheme    *runtime.Scheme
+	Recorder  record.EventRecorder
+	Log       logr.Logger
+	Settings  *stores.ConfigStore
+	requeue   chan event.GenericEvent
+
+	// Clients for the remote clusters
+	remotes     map[string]remoteClient
+	remotesLock sync.Mutex
+
+	// Objects read with lookup, watched dynamically in a cache scoped to the lookup namespaces
+	lookups    *lookupTracker
+	controller controller.Controller
+	cache      cache.Cache
+
+	// Repository secrets in the tenant namespaces, which are not cached by the manager
+	repositories cache.Cache
+
+	// Requests to reflect the source namespaces of all instances
+	sourceNamespaces chan struct{}
+
+	// Options the controller was set up with
+	options v1alpha1.ControllerOptions
 }
 
-func (i *TenancyController) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+func (i *TenancyController) SetupWithManager(ctx context.Context, mgr ctrl.Manager) (err error) {
 	i.requeue = make(chan event.GenericEvent)
+	i.sourceNamespaces = make(chan struct{}, 1)
+	i.lookups = newLookupTracker()
+	i.options = i.Settings.Get().Controllers.Tenant
+
+	i.repositories, err = cache.New(mgr.GetConfig(), cache.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.Secret{}: {Label: repositorySecretSelector()},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = mgr.Add(i.repositories); err != nil {
+		return err
+	}
+
+	i.cache, err = cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:            mgr.GetScheme(),
+		Mapper:            mgr.GetRESTMapper(),
+		DefaultNamespaces: lookupNamespaces(i.Settings.Get()),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = mgr.Add(i.cache); err != nil {
+		return err
+	}
+
+	if err = mgr.Add(manager.RunnableFunc(i.runSourceNamespaces)); err != nil {
+		return err
+	}
+
 	go func() {
 		for {
 			select {
@@ -58,8 +126,8 @@ func (i *TenancyController) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 		}
 	}()
 
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&capsulev1beta2.Tenant{}).
+	i.controller, err = ctrl.NewControllerManagedBy(mgr).
+		For(&capsulev1beta2.Tenant{}, builder.WithPredicates(i.tenantPredicate())).
 		Watches(
 			&corev1.ServiceAccount{},
 			handler.EnqueueRequestForOwner(
@@ -81,6 +149,23 @@ func (i *TenancyController) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 				mgr.GetRESTMapper(),
 				&capsulev1beta2.Tenant{},
 			)).
+		// Status and health updates of the applications are not relevant
+		Watches(
+			&argocdapi.Application{},
+			handler.EnqueueRequestForOwner(
+				mgr.GetScheme(),
+				mgr.GetRESTMapper(),
+				&capsulev1beta2.Tenant{},
+			),
+			builder.WithPredicates(applicationSpecPredicate())).
+		Watches(
+			&argocdapi.ApplicationSet{},
+			handler.EnqueueRequestForOwner(
+				mgr.GetScheme(),
+				mgr.GetRESTMapper(),
+				&capsulev1beta2.Tenant{},
+			),
+			builder.WithPredicates(applicationSpecPredicate())).
 		//Owns(&argocdapi.AppProject{}).
 		Watches(
 			&argocdapi.AppProject{},
@@ -89,11 +174,42 @@ func (i *TenancyController) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 				mgr.GetRESTMapper(),
 				&capsulev1beta2.Tenant{},
 			)).
-		// Whenever a translator is updated, we need to reconcile all tenants
-		Watches(&configv1alpha1.ArgoTranslator{}, i.TenantRequeueHandler()).
+		// Reconcile the tenant owning the namespace of a repository secret
+		WatchesRawSource(
+			source.Kind(i.repositories, &corev1.Secret{}),
+			i.TenantNamespaceHandler(),
+			builder.WithPredicates(repositorySecretPredicate()),
+		).
+		// Reconcile the Tenant when the labels of its namespaces change (project variant destinations)
+		Watches(
+			&corev1.Namespace{},
+			i.TenantNamespaceHandler(),
+			builder.WithPredicates(namespaceLabelsPredicate()),
+		).
+		// Reconcile the Tenant renaming the appproject of an Application
+		Watches(
+			&argocdapi.Application{},
+			i.ProjectRenameHandler(),
+			builder.WithPredicates(applicationProjectPredicate()),
+		).
+		// Tenants of the same project group share the appproject
+		Watches(&capsulev1beta2.Tenant{}, i.ProjectGroupHandler()).
+		Watches(
+			&configv1alpha1.ArgoTranslator{},
+			i.TranslatorTenantsHandler(),
+			builder.WithPredicates(i.translatorPredicate()),
+		).
+		// Reconcile the Tenants of the translators using a template library
+		Watches(&configv1alpha1.ArgoTemplateLibrary{}, i.TemplateLibraryHandler()).
 		// Reconcile When Configuration Changes
 		WatchesRawSource(&source.Channel{Source: i.requeue}, i.TenantRequeueHandler()).
-		Complete(i)
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: i.options.Concurrency(),
+			RateLimiter:             i.options.RateLimiter(),
+		}).
+		Build(i)
+
+	return err
 }
 
 // Handler to reconcile all Tenants
@@ -110,6 +226,10 @@ func (i *TenancyController) TenantRequeueHandler() handler.EventHandler {
 		// Enqueue each tenant for reconciliation
 		var requests []reconcile.Request
 		for _, tenant := range tenants.Items {
+			if !i.managesTenant(&tenant) {
+				continue
+			}
+
 			requests = append(requests, reconcile.Request{
 				NamespacedName: types.NamespacedName{
 					Name: tenant.Name,
@@ -121,19 +241,257 @@ func (i *TenancyController) TenantRequeueHandler() handler.EventHandler {
 	})
 }
 
+// Handler to reconcile the Tenants a Translator applies to (or was applied to)
+func (i *TenancyController) TranslatorTenantsHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		translator, ok := a.(*configv1alpha1.ArgoTranslator)
+		if !ok {
+			return nil
+		}
+
+		return i.translatorTenantRequests(ctx, []*configv1alpha1.ArgoTranslator{translator})
+	})
+}
+
+// Handler to reconcile the Tenants of the translators using the template library
+func (i *TenancyController) TemplateLibraryHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		translators := &configv1alpha1.ArgoTranslatorList{}
+		if err := i.Client.List(ctx, translators); err != nil {
+			i.Log.Error(err, "Failed to list translators for reconciliation")
+			return nil
+		}
+
+		var using []*configv1alpha1.ArgoTranslator
+		for idx := range translators.Items {
+			translator := &translators.Items[idx]
+			if utils.ContainsString(translator.Spec.Libraries, a.GetName()) && i.Settings.Get().SelectsTranslator(translator) {
+				using = append(using, translator)
+			}
+		}
+
+		if len(using) == 0 {
+			return nil
+		}
+
+		return i.translatorTenantRequests(ctx, using)
+	})
+}
+
+// Requests for the tenants selected by (or applied from) the translators
+func (i *TenancyController) translatorTenantRequests(
+	ctx context.Context,
+	translators []*configv1alpha1.ArgoTranslator,
+) []reconcile.Request {
+	tenants := &capsulev1beta2.TenantList{}
+	if err := i.Client.List(ctx, tenants); err != nil {
+		i.Log.Error(err, "Failed to list tenants for reconciliation")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for idx := range tenants.Items {
+		tenant := &tenants.Items[idx]
+		if !i.managesTenant(tenant) {
+			continue
+		}
+
+		for _, translator := range translators {
+			// Tenants are also reconciled when the selection fails, the translator no longer applies to them
+			selected, err := translator.SelectsTenant(tenant)
+			if !selected && err == nil && !utils.ContainsString(translator.GetTenantNames(), tenant.Name) {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: tenant.Name,
+				},
+			})
+
+			break
+		}
+	}
+
+	return requests
+}
+
+// Only consider secrets which are (or were) labeled as repository
+func repositorySecretPredicate() predicate.Funcs {
+	isRepository := func(obj client.Object) bool {
+		_, ok := obj.GetLabels()[meta.RepositoryLabel]
+
+		return ok
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return isRepository(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return isRepository(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return isRepository(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return isRepository(e.ObjectOld) || isRepository(e.ObjectNew)
+		},
+	}
+}
+
+// Selects the repository secrets in all namespaces
+func repositorySecretSelector() labels.Selector {
+	requirement, _ := labels.NewRequirement(meta.RepositoryLabel, selection.Exists, nil)
+
+	return labels.NewSelector().Add(*requirement)
+}
+
+// Only consider spec and label changes of applications. The application CRD has no status subresource, so
+// the generation also changes with the status
+func applicationSpecPredicate() predicate.Funcs {
+	spec := func(obj client.Object) interface{} {
+		switch app := obj.(type) {
+		case *argocdapi.Application:
+			return app.Spec
+		case *argocdapi.ApplicationSet:
+			return app.Spec
+		}
+
+		return nil
+	}
+
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(spec(e.ObjectOld), spec(e.ObjectNew)) ||
+				!reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) ||
+				!e.ObjectNew.GetDeletionTimestamp().Equal(e.ObjectOld.GetDeletionTimestamp())
+		},
+	}
+}
+
+// Only consider label changes of namespaces, new and removed namespaces are reflected in the tenant status
+func namespaceLabelsPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return !reflect.DeepEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels())
+		},
+	}
+}
+
+// Returns true if the tenant is selected by this controller or still carries its finalizer
+func (i *TenancyController) managesTenant(tenant *capsulev1beta2.Tenant) bool {
+	return i.Settings.Get().SelectsTenant(tenant) || controllerutil.ContainsFinalizer(tenant, meta.Finalizer())
+}
+
+// Only consider tenants which are managed by this controller
+func (i *TenancyController) tenantPredicate() predicate.Funcs {
+	manages := func(obj client.Object) bool {
+		tenant, ok := obj.(*capsulev1beta2.Tenant)
+
+		return ok && i.managesTenant(tenant)
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return manages(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return manages(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return manages(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return manages(e.ObjectOld) || manages(e.ObjectNew)
+		},
+	}
+}
+
+// Only consider translators which are (or were) selected by this controller
+func (i *TenancyController) translatorPredicate() predicate.Funcs {
+	selects := func(obj client.Object) bool {
+		translator, ok := obj.(*configv1alpha1.ArgoTranslator)
+
+		return ok && i.Settings.Get().SelectsTranslator(translator)
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return selects(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return selects(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return selects(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return selects(e.ObjectOld) || selects(e.ObjectNew)
+		},
+	}
+}
+
+// Handler to reconcile the Tenant which owns the namespace of the object
+func (i *TenancyController) TenantNamespaceHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		tenants := &capsulev1beta2.TenantList{}
+		err := i.Client.List(ctx, tenants)
+		if err != nil {
+			i.Log.Error(err, "Failed to list tenants for reconciliation")
+			return nil
+		}
+
+		// Namespaces belong to the tenant themselves
+		owned := a.GetNamespace()
+		if _, ok := a.(*corev1.Namespace); ok {
+			owned = a.GetName()
+		}
+
+		for _, tenant := range tenants.Items {
+			if !i.managesTenant(&tenant) {
+				continue
+			}
+
+			for _, namespace := range tenant.Status.Namespaces {
+				if namespace == owned {
+					return []reconcile.Request{{
+						NamespacedName: types.NamespacedName{
+							Name: tenant.Name,
+						},
+					}}
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
 func (i *TenancyController) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	log := i.Log.WithValues("tenant", request.Name)
 
 	log.V(7).Info("controller configuration", "config", i.Settings.Get())
 
+	// Lookups are tracked again while rendering the templates
+	if i.lookups != nil {
+		i.lookups.reset(request.Name)
+	}
+
 	origin := &capsulev1beta2.Tenant{}
 	if err := i.Client.Get(ctx, request.NamespacedName, origin); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Tenant is reconciled by another controller, release it
+	if !i.Settings.Get().SelectsTenant(origin) {
+		return ctrl.Result{}, i.releaseTenant(ctx, log, origin)
+	}
+
+	ctx, due := withRequeue(ctx)
+
 	log.V(5).Info("reconciling addons")
 	translators, err := i.reconcile(ctx, log, origin)
 	if err != nil {
+		var pending *ccaerrrors.ApplicationsPending
+		if errors.As(err, &pending) {
+			log.V(3).Info("waiting for applications", "appproject", pending.Project, "applications", pending.Applications)
+
+			return ctrl.Result{RequeueAfter: applicationsPendingRequeue}, nil
+		}
+
+		var renaming *ccaerrrors.ProjectRenameInProgress
+		if errors.As(err, &renaming) {
+			log.V(3).Info("waiting for applications to leave the previous appproject", "appproject", renaming.From, "applications", renaming.Applications)
+
+			return ctrl.Result{RequeueAfter: projectRenameRequeue}, nil
+		}
+
 		log.Error(err, "reconcile error")
 		return ctrl.Result{}, nil
 	}
@@ -141,7 +499,7 @@ func (i *TenancyController) Reconcile(ctx context.Context, request ctrl.Request)
 	if !origin.ObjectMeta.DeletionTimestamp.IsZero() || len(translators) == 0 {
 		// Wait until all translators have finished
 		if len(meta.GetTranslatingFinalizers(origin)) == 0 {
-			if controllerutil.ContainsFinalizer(origin, meta.ControllerFinalizer) {
+			if controllerutil.ContainsFinalizer(origin, meta.Finalizer()) {
 				log.V(5).Info("finalizing tenant")
 				err := i.lifecycle(ctx, log, origin)
 				if err != nil {
@@ -154,7 +512,7 @@ func (i *TenancyController) Reconcile(ctx context.Context, request ctrl.Request)
 			}, nil
 		}
 
-		if controllerutil.ContainsFinalizer(origin, meta.ControllerFinalizer) {
+		if controllerutil.ContainsFinalizer(origin, meta.Finalizer()) {
 			log.V(5).Info("finalizing tenant")
 			err := i.lifecycle(ctx, log, origin)
 			if err != nil {
@@ -167,8 +525,8 @@ func (i *TenancyController) Reconcile(ctx context.Context, request ctrl.Request)
 		}, nil
 	}
 
-	if !controllerutil.ContainsFinalizer(origin, meta.ControllerFinalizer) {
-		controllerutil.AddFinalizer(origin, meta.ControllerFinalizer)
+	if !controllerutil.ContainsFinalizer(origin, meta.Finalizer()) {
+		controllerutil.AddFinalizer(origin, meta.Finalizer())
 		err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 			if err := i.Client.Update(ctx, origin); err != nil {
 				return err
@@ -181,10 +539,33 @@ func (i *TenancyController) Reconcile(ctx context.Context, request ctrl.Request)
 		}
 	}
 
-	return ctrl.Result{}, nil
+	// Reconcile again after the resync interval, even if nothing changed, or earlier when requested
+	return ctrl.Result{RequeueAfter: due.after(time.Now(), i.options.Resync(defaultResyncInterval))}, nil
 
 }
 
+// Removes the finalizer of this controller from a tenant which is no longer selected. The provisioned
+// assets are kept, so they can be adopted by the controller selecting the tenant.
+func (i *TenancyController) releaseTenant(ctx context.Context, log logr.Logger, tenant *capsulev1beta2.Tenant) error {
+	if !controllerutil.ContainsFinalizer(tenant, meta.Finalizer()) {
+		return nil
+	}
+
+	log.V(3).Info("tenant no longer selected, releasing")
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(tenant), tenant); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+
+		if !controllerutil.RemoveFinalizer(tenant, meta.Finalizer()) {
+			return nil
+		}
+
+		return i.Client.Update(ctx, tenant)
+	})
+}
+
 // Reconcile all the assets
 func (i *TenancyController) reconcile(
 	ctx context.Context,
@@ -200,18 +581,56 @@ func (i *TenancyController) reconcile(
 
 	// Fetch Translators Applying to the Tenant
 	var unmatchedTranslators []*configv1alpha1.ArgoTranslator
-	translators, unmatchedTranslators, err = i.aggregateConfigTranslators(allTranslators, tenant)
+	translators, unmatchedTranslators = i.aggregateConfigTranslators(allTranslators, tenant)
 	log.V(3).Info("matched translators", "count", len(translators))
+
+	// Skip the reconciliation, when the inputs did not change since the last successful reconciliation
+	hash, err := i.inputHash(ctx, tenant, translators, unmatchedTranslators)
 	if err != nil {
 		return translators, err
 	}
+
+	if i.inputsUnchanged(ctx, tenant, translators, hash) {
+		log.V(5).Info("inputs unchanged, skipping reconcile", "hash", hash)
+
+		return translators, nil
+	}
+
+	ctx = withInputHash(ctx, hash)
+
 	unmatchedTranslatorMap := make(map[string]*configv1alpha1.ArgoTranslator)
 	for _, translator := range unmatchedTranslators {
 		unmatchedTranslatorMap[translator.Name] = translator
 	}
 
+	// Select the Argo Instance (Migrates the tenant when the instance changed)
+	var reconcileErr error
+	if tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+		reconcileErr = i.reconcileArgoInstance(ctx, log, tenant, translators)
+	} else {
+		// Apply the deletion policy to the Applications of the tenant
+		reconcileErr = i.reconcileArgoDeletionPolicy(ctx, log, tenant)
+	}
+
+	// Remove the policies from the previous rbac target, when the target changed
+	if reconcileErr == nil {
+		reconcileErr = i.reconcileArgoPolicyTarget(ctx, log, tenant)
+	}
+
 	// Reconcile the Argo Assets
-	reconcileErr := i.reconcileArgoProject(ctx, log, tenant, translators, unmatchedTranslatorMap)
+	if reconcileErr == nil {
+		reconcileErr = i.reconcileArgoProject(ctx, log, tenant, translators, unmatchedTranslatorMap)
+	}
+
+	// Migrate from the previous appproject when the project name changed
+	if reconcileErr == nil && tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+		reconcileErr = i.reconcileArgoProjectRename(ctx, log, tenant, translators)
+	}
+
+	// Reconcile the Project Variants
+	if reconcileErr == nil {
+		reconcileErr = i.reconcileArgoProjectVariants(ctx, log, tenant, translators)
+	}
 
 	// Status handling always runs even when reconciliation failed
 	// Evaluate Condition
@@ -223,7 +642,8 @@ func (i *TenancyController) reconcile(
 		err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 			_, err = controllerutil.CreateOrUpdate(ctx, i.Client, tenant.DeepCopy(), func() error {
 
-				if !tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+				// Keep reporting the progress of the deletion
+				if !tenant.ObjectMeta.DeletionTimestamp.IsZero() && condition.Reason != meta.ProgressingReason {
 					selected.RemoveTenantCondition(tenant.Name)
 				} else {
 					selected.UpdateTenantCondition(configv1alpha1.TenantStatus{
@@ -267,6 +687,18 @@ func (i *TenancyController) reconcile(
 		}
 	}
 
+	// The deletion of the tenant waits for its Applications
+	var pending *ccaerrrors.ApplicationsPending
+	if errors.As(reconcileErr, &pending) {
+		return translators, reconcileErr
+	}
+
+	// The rename of the appproject waits for its Applications
+	var renaming *ccaerrrors.ProjectRenameInProgress
+	if errors.As(reconcileErr, &renaming) {
+		return translators, reconcileErr
+	}
+
 	// Finally return if reconciliation had an error.
 	if reconcileErr != nil {
 		return translators, err
@@ -291,6 +723,12 @@ func (i *TenancyController) handleCondition(
 	case *ccaerrrors.ObjectAlreadyExists:
 		// Custom condition for ObjectAlreadyExistsError
 		condition = meta.NewAlreadyExistsCondition(tenant, err.Error())
+	case *ccaerrrors.ApplicationsPending:
+		// Deletion waits for the applications
+		condition = meta.NewProgressingCondition(tenant, err.Error())
+	case *ccaerrrors.ProjectRenameInProgress:
+		// Previous appproject is not yet empty
+		condition = meta.NewProgressingCondition(tenant, err.Error())
 	default:
 		// Default NotReady condition for other errors
 		condition = meta.NewNotReadyCondition(tenant, reconcileError.Error())
@@ -300,7 +738,8 @@ func (i *TenancyController) handleCondition(
 }
 
 // Selects all the translators from the configuration, which match the tenant's labels
-// Returns all translators to run garbage collection on them
+// Returns all translators to run garbage collection on them. Translators with an invalid selector or match
+// expression don't match the tenant, the error is reported on the translator's status
 //
 //nolint:nakedret
 func (i *TenancyController) aggregateConfigTranslators(
@@ -309,12 +748,9 @@ func (i *TenancyController) aggregateConfigTranslators(
 ) (
 	matchedTranslators []*v1alpha1.ArgoTranslator,
 	unmatchedTranslators []*v1alpha1.ArgoTranslator,
-	err error,
 ) {
 	matchedTranslators = make([]*v1alpha1.ArgoTranslator, 0)
 	unmatchedTranslators = make([]*v1alpha1.ArgoTranslator, 0)
-	tenantLabels := labels.Set(tenant.Labels)
-
 	for _, trans := range allTranslators.Items {
 		translator := trans
 
@@ -323,33 +759,36 @@ func (i *TenancyController) aggregateConfigTranslators(
 			continue
 		}
 
-		if translator.Spec.Selector == nil {
+		// Translators handled by another controller are not applied
+		if !i.Settings.Get().SelectsTranslator(&translator) {
 			unmatchedTranslators = append(unmatchedTranslators, &translator)
 			continue
 		}
 
-		// Convert LabelSelector to a labels.Selector
-		var selector labels.Selector
-		selector, err = metav1.LabelSelectorAsSelector(translator.Spec.Selector)
+		// Check if the translator applies to the tenant (selector and tenant annotations)
+		selected, err := translator.SelectsTenant(tenant)
 		if err != nil {
-
-			return
+			i.Log.V(5).Info("translator selection failed, not applying translator", "tenant", tenant.Name, "translator", translator.Name, "error", err.Error())
 		}
 
-		// Check if tenant's labels match the translator's selector
-		if selector.Matches(tenantLabels) {
+		if selected {
 			matchedTranslators = append(matchedTranslators, &translator)
 		} else {
 			unmatchedTranslators = append(unmatchedTranslators, &translator)
 		}
 	}
 
+	// The order of the list is not guaranteed, translators are applied by name to render the same assets on every reconcile
+	sort.Slice(matchedTranslators, func(a, b int) bool {
+		return matchedTranslators[a].Name < matchedTranslators[b].Name
+	})
+
 	return
 }
 
 // Patch the tenant from the argocd configmap
 func (i *TenancyController) lifecycle(ctx context.Context, log logr.Logger, tenant *capsulev1beta2.Tenant) (err error) {
-	if !controllerutil.ContainsFinalizer(tenant, meta.ControllerFinalizer) {
+	if !controllerutil.ContainsFinalizer(tenant, meta.Finalizer()) {
 		return nil
 	}
 
@@ -357,8 +796,11 @@ func (i *TenancyController) lifecycle(ctx context.Context, log logr.Logger, tena
 	log.V(7).Info("lifecycling argo components")
 	err = i.lifecycleArgo(ctx, tenant)
 
+	// Rendered outputs of the tenant are no longer needed
+	rendercache.ForgetScope(tenant.UID)
+
 	// Remove Finalizers after tenant
-	controllerutil.RemoveFinalizer(tenant, meta.ControllerFinalizer)
+	controllerutil.RemoveFinalizer(tenant, meta.Finalizer())
 	if err := i.Client.Update(ctx, tenant); err != nil {
 		return err
 	}
@@ -366,29 +808,28 @@ func (i *TenancyController) lifecycle(ctx context.Context, log logr.Logger, tena
 		return err
 	}
 
+	// Remove the tenant from the source namespaces
+	i.requestSourceNamespaces()
+
 	return nil
 }
 
 func (i *TenancyController) lifecycleArgo(ctx context.Context, tenant *capsulev1beta2.Tenant) (err error) {
-	// Update existing configmap with new csv
-	if !meta.TenantDecoupleProject(tenant) {
+	log := i.Log.WithValues("tenant", tenant.Name)
 
-		configmap := &corev1.ConfigMap{}
-		err := i.Client.Get(ctx, client.ObjectKey{
-			Name:      i.Settings.Get().Argo.RBACConfigMap,
-			Namespace: i.Settings.Get().Argo.Namespace},
-			configmap,
-		)
+	// Remove the tenant from the policies (other tenants may still share the appproject)
+	if !meta.TenantDecoupleProject(tenant) {
+		members, err := i.projectMembers(ctx, tenant, nil)
 		if err != nil {
 			return err
 		}
 
-		_, err = controllerutil.CreateOrUpdate(ctx, i.Client, configmap, func() error {
-			delete(configmap.Data, argo.ArgoPolicyName(tenant))
+		if err := i.reflectArgoRBAC(ctx, log, tenant, members); err != nil {
+			return err
+		}
 
-			return nil
-		})
-		if err != nil {
+		// Remove the local accounts of the tenant
+		if err := i.reconcileArgoAccounts(ctx, log, tenant, nil); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,364 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Minted token which still has to be stored in the tenant namespace
+type mintedToken struct {
+	// Label identifying the subject of the token (key and value)
+	label string
+	value string
+
+	secret client.ObjectKey
+	data   map[string][]byte
+}
+
+// Creates the local accounts from the translators for the tenant and mints their API tokens.
+// Accounts which are no longer defined are removed together with their tokens.
+func (i *TenancyController) reconcileArgoAccounts(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) error {
+	desired := make(map[string]*v1alpha1.ArgocdAccountTranslator)
+	for _, translator := range translators {
+		for idx := range translator.Spec.Accounts {
+			account := &translator.Spec.Accounts[idx]
+			desired[argo.AccountName(tenant, account.Name)] = account
+		}
+	}
+
+	removed, err := i.reflectArgoAccounts(ctx, log, tenant, desired)
+	if err != nil {
+		return err
+	}
+
+	return i.reflectArgoAccountTokens(ctx, log, tenant, desired, removed)
+}
+
+// Reflects the accounts in the argocd configmap, returns the accounts which were removed
+func (i *TenancyController) reflectArgoAccounts(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	desired map[string]*v1alpha1.ArgocdAccountTranslator,
+) (removed []string, err error) {
+	cfg := i.settings(tenant)
+	tracking := meta.ManagedParamAnnotation("accounts." + tenant.Name)
+
+	accounts := make([]string, 0, len(desired))
+	for account := range desired {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	configmap := &corev1.ConfigMap{}
+	err = i.Client.Get(ctx, client.ObjectKey{
+		Name:      cfg.Argo.ConfigMap,
+		Namespace: cfg.Argo.Namespace}, configmap)
+	if err != nil {
+		// Nothing to clean up
+		if k8serrors.IsNotFound(err) && len(accounts) == 0 {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	log.V(7).Info("reflecting accounts", "configmap", configmap.Name, "accounts", accounts)
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() (conflictErr error) {
+		_, conflictErr = controllerutil.CreateOrUpdate(ctx, i.Client, configmap, func() error {
+			if configmap.Data == nil {
+				configmap.Data = make(map[string]string)
+			}
+
+			previous := meta.GetAnnotationList(configmap, tracking)
+
+			// Handle Force, if an account already exists which was not added by the controller
+			for _, account := range accounts {
+				if _, ok := configmap.Data[argo.AccountKey(account)]; ok && !utils.ContainsString(previous, account) && !i.ForceTenant(tenant) {
+					return fmt.Errorf("account %s already exists in %s", account, configmap.Name)
+				}
+			}
+
+			removed = nil
+			for _, account := range previous {
+				if _, ok := desired[account]; !ok {
+					delete(configmap.Data, argo.AccountKey(account))
+					removed = append(removed, account)
+				}
+			}
+
+			for _, account := range accounts {
+				configmap.Data[argo.AccountKey(account)] = argo.AccountCapabilityAPIKey
+			}
+
+			meta.SetAnnotationList(configmap, tracking, accounts)
+
+			return nil
+		})
+
+		return
+	})
+
+	return removed, err
+}
+
+// Mints the API tokens for the accounts and stores them in the tenant namespaces. A token is only registered
+// in the argocd-secret once it was stored, tokens are rotated before they expire. Tokens of removed accounts
+// are revoked.
+func (i *TenancyController) reflectArgoAccountTokens(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	desired map[string]*v1alpha1.ArgocdAccountTranslator,
+	removed []string,
+) error {
+	cfg := i.settings(tenant)
+
+	// Current token secrets of the tenant
+	secrets := &corev1.SecretList{}
+	if err := i.Client.List(ctx, secrets,
+		client.MatchingLabels{meta.ManagedTenantLabel: tenant.Name},
+		client.HasLabels{meta.ManagedAccountLabel},
+	); err != nil {
+		return err
+	}
+
+	current := make(map[string]string)
+	revoked := make(map[string][]string)
+	var obsolete []*corev1.Secret
+	for idx := range secrets.Items {
+		secret := &secrets.Items[idx]
+		account := secret.Labels[meta.ManagedAccountLabel]
+		id, _ := argo.AccountTokenID(string(secret.Data["token"]))
+
+		target, ok := desired[account]
+		if ok && target.Token != nil && tokenSecret(account, target.Token) == client.ObjectKeyFromObject(secret) {
+			current[account] = id
+
+			continue
+		}
+
+		revoked[account] = append(revoked[account], id)
+		obsolete = append(obsolete, secret)
+	}
+
+	var tokens []string
+	for account, target := range desired {
+		if target.Token == nil {
+			continue
+		}
+
+		if !utils.ContainsString(tenant.Status.Namespaces, target.Token.Namespace) {
+			return fmt.Errorf("namespace %s for the token of account %s is not part of tenant %s", target.Token.Namespace, account, tenant.Name)
+		}
+
+		tokens = append(tokens, account)
+	}
+	sort.Strings(tokens)
+
+	if len(tokens) == 0 && len(removed) == 0 && len(revoked) == 0 {
+		return nil
+	}
+
+	argoSecret := &corev1.Secret{}
+	if err := i.Client.Get(ctx, client.ObjectKey{Name: cfg.Argo.Secret, Namespace: cfg.Argo.Namespace}, argoSecret); err != nil {
+		return err
+	}
+
+	// Mint and store the tokens which are missing or due for rotation
+	now := time.Now()
+	issued := make(map[string]argo.AccountToken)
+
+	var errs []error
+	for _, account := range tokens {
+		registered, err := argo.ParseAccountTokens(argoSecret.Data[argo.AccountTokensKey(account)])
+		if err != nil {
+			return err
+		}
+
+		if id, ok := current[account]; ok {
+			if rotation, ok := argo.AccountTokenRotation(registered, id); ok && (rotation.IsZero() || now.Before(rotation)) {
+				requeueAt(ctx, rotation)
+
+				continue
+			}
+		}
+
+		target := desired[account]
+
+		signed, token, err := argo.MintAccountToken(account, argoSecret.Data[argo.ServerSignatureKey], target.Token.GetExpiresIn(), now)
+		if err != nil {
+			return fmt.Errorf("failed to mint token for account %s: %w", account, err)
+		}
+
+		minted := mintedToken{
+			label:  meta.ManagedAccountLabel,
+			value:  account,
+			secret: tokenSecret(account, target.Token),
+			data: map[string][]byte{
+				"account": []byte(account),
+				"token":   []byte(signed),
+			},
+		}
+
+		log.V(5).Info("storing account token", "account", account, "secret", minted.secret.Name, "namespace", minted.secret.Namespace)
+		if err := i.storeArgoToken(ctx, log, tenant, minted); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		issued[account] = token
+		requeueAt(ctx, argo.TokenRotation(token.IssuedAt, token.ExpiresAt))
+	}
+
+	if len(issued) == 0 && len(removed) == 0 && len(revoked) == 0 {
+		return errors.Join(errs...)
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(argoSecret), argoSecret); err != nil {
+			return err
+		}
+
+		if argoSecret.Data == nil {
+			argoSecret.Data = make(map[string][]byte)
+		}
+
+		for _, account := range removed {
+			delete(argoSecret.Data, argo.AccountTokensKey(account))
+		}
+
+		for account, ids := range revoked {
+			if _, ok := argoSecret.Data[argo.AccountTokensKey(account)]; !ok {
+				continue
+			}
+
+			registered, err := argo.ParseAccountTokens(argoSecret.Data[argo.AccountTokensKey(account)])
+			if err != nil {
+				return err
+			}
+
+			if err := setAccountTokens(argoSecret, account, argo.PruneAccountTokens(registered, now, ids...)); err != nil {
+				return err
+			}
+		}
+
+		// The rotated token stays valid until it expires, so running pipelines are not interrupted
+		for account, token := range issued {
+			registered, err := argo.ParseAccountTokens(argoSecret.Data[argo.AccountTokensKey(account)])
+			if err != nil {
+				return err
+			}
+
+			if err := setAccountTokens(argoSecret, account, append(argo.PruneAccountTokens(registered, now), token)); err != nil {
+				return err
+			}
+		}
+
+		return i.Client.Update(ctx, argoSecret)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range obsolete {
+		log.V(5).Info("removing account token", "secret", secret.Name, "namespace", secret.Namespace)
+		if err := i.Client.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Stores the minted token in the secret within the tenant namespace
+func (i *TenancyController) storeArgoToken(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	minted mintedToken,
+) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      minted.secret.Name,
+			Namespace: minted.secret.Namespace,
+		},
+	}
+
+	err := i.getManagedObject(ctx, secret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	// Handle Force, if an object already exists with the same name
+	if !meta.HasTenantOwnerReference(secret, tenant) {
+		if !i.ForceTenant(tenant) && !k8serrors.IsNotFound(err) {
+			log.V(5).Info("token secret already present, not overriding", "secret", secret.Name, "namespace", secret.Namespace)
+
+			return ccaerrrors.NewObjectAlreadyExistsError(secret)
+		}
+	}
+
+	_, err = i.createOrUpdateManagedObject(ctx, secret, func() error {
+		labels := meta.WithTranslatorTrackingLabels(secret, tenant)
+		labels[minted.label] = minted.value
+		secret.SetLabels(labels)
+
+		secret.Data = minted.data
+
+		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), secret, tenant)
+	})
+
+	return err
+}
+
+// Secret in the tenant namespace holding the token, defaults to the given name
+func tokenSecret(name string, token *v1alpha1.ArgocdAccountToken) client.ObjectKey {
+	if token.SecretName != "" {
+		name = token.SecretName
+	}
+
+	return client.ObjectKey{Name: name, Namespace: token.Namespace}
+}
+
+// Sets the registered tokens of the account in the argocd secret
+func setAccountTokens(secret *corev1.Secret, account string, tokens []argo.AccountToken) error {
+	if len(tokens) == 0 {
+		delete(secret.Data, argo.AccountTokensKey(account))
+
+		return nil
+	}
+
+	raw, err := argo.FormatAccountTokens(tokens)
+	if err != nil {
+		return err
+	}
+
+	secret.Data[argo.AccountTokensKey(account)] = raw
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	translatorctl "github.com/peak-scale/capsule-argo-addon/internal/controllers/translator"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Creates or updates the Applications and ApplicationSets from the translators for the tenant. When several
+// translators render the same object, the first translator (by name) keeps it and a conflict is reported
+func (i *TenancyController) reconcileArgoApplications(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) error {
+	// Translator rendering each desired object
+	desired := make(map[string]string)
+
+	var conflicts []error
+	for _, translator := range translators {
+		if len(translator.Spec.Applications) == 0 {
+			continue
+		}
+
+		renderer, err := i.translatorRenderer(ctx, tenant, translator)
+		if err != nil {
+			return err
+		}
+
+		for idx := range translator.Spec.Applications {
+			application := &translator.Spec.Applications[idx]
+
+			obj, err := renderer.Application(application)
+			if err != nil {
+				return fmt.Errorf("failed to render application %s from translator %s: %w", application.Name, translator.Name, err)
+			}
+
+			// Namespace is defaulted on reconcile, the key must match the reconciled object
+			namespace, err := i.applicationNamespace(tenant, obj.GetNamespace())
+			if err != nil {
+				return err
+			}
+
+			obj.SetNamespace(namespace)
+
+			key := applicationKey(obj)
+			if owner, ok := desired[key]; ok {
+				log.Info("application is rendered by several translators", "name", obj.GetName(), "translator", translator.Name, "conflict", owner)
+				conflicts = append(conflicts, ccaerrrors.NewApplicationConflictError(obj, translator.Name, owner))
+
+				continue
+			}
+
+			if err := i.reconcileArgoApplication(ctx, log, tenant, translator, obj); err != nil {
+				return err
+			}
+
+			desired[key] = translator.Name
+		}
+	}
+
+	if err := i.lifecycleArgoApplications(ctx, log, tenant, desired, meta.TenantDecoupleProject(tenant) && len(translators) == 0); err != nil {
+		return err
+	}
+
+	return errors.Join(conflicts...)
+}
+
+// Creates or updates a single rendered Application or ApplicationSet
+func (i *TenancyController) reconcileArgoApplication(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translator *v1alpha1.ArgoTranslator,
+	rendered client.Object,
+) error {
+	namespace, err := i.applicationNamespace(tenant, rendered.GetNamespace())
+	if err != nil {
+		return err
+	}
+
+	rendered.SetNamespace(namespace)
+	project := meta.TenantProjectName(tenant)
+
+	// Prepare the object and the spec for the given kind
+	var current client.Object
+	var mutateSpec func()
+	switch obj := rendered.(type) {
+	case *argocdv1alpha1.Application:
+		obj.Spec.Project = project
+		app := &argocdv1alpha1.Application{}
+		mutateSpec = func() { app.Spec = obj.Spec }
+		current = app
+	case *argocdv1alpha1.ApplicationSet:
+		obj.Spec.Template.Spec.Project = project
+		appset := &argocdv1alpha1.ApplicationSet{}
+		mutateSpec = func() { appset.Spec = obj.Spec }
+		current = appset
+	default:
+		return fmt.Errorf("unsupported application type %T", rendered)
+	}
+
+	current.SetName(rendered.GetName())
+	current.SetNamespace(rendered.GetNamespace())
+
+	log.V(7).Info("reconciling application", "name", current.GetName(), "namespace", namespace, "translator", translator.Name)
+
+	err = i.Client.Get(ctx, client.ObjectKeyFromObject(current), current)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	// Handle Force, if an object already exists with the same name
+	if !meta.HasTenantOwnerReference(current, tenant) {
+		if !i.ForceTenant(tenant) && !k8serrors.IsNotFound(err) {
+			log.V(5).Info("application already present, not overriding", "name", current.GetName(), "namespace", namespace)
+
+			return ccaerrrors.NewObjectAlreadyExistsError(current)
+		}
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, current, func() error {
+		labels := meta.WithTranslatorTrackingLabels(current, tenant)
+		for key, value := range rendered.GetLabels() {
+			labels[key] = value
+		}
+		labels[meta.ManagedTranslatorLabel] = translator.Name
+		current.SetLabels(labels)
+
+		annotations := current.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		for key, value := range rendered.GetAnnotations() {
+			annotations[key] = value
+		}
+		current.SetAnnotations(annotations)
+
+		finalizers := append(rendered.GetFinalizers(), meta.TranslatorFinalizer(translator.Name))
+		for _, finalizer := range finalizers {
+			controllerutil.AddFinalizer(current, finalizer)
+		}
+
+		mutateSpec()
+
+		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), current, tenant)
+	})
+
+	return err
+}
+
+// Removes (or decouples) the Applications and ApplicationSets of the tenant which are no longer desired
+func (i *TenancyController) lifecycleArgoApplications(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	desired map[string]string,
+	decouple bool,
+) error {
+	objs, err := translatorctl.ListTranslatedApplications(ctx, i.Client, client.MatchingLabels{
+		meta.ManagedTenantLabel: tenant.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		if _, ok := desired[applicationKey(obj)]; ok {
+			continue
+		}
+
+		if !meta.HasTenantOwnerReference(obj, tenant) {
+			continue
+		}
+
+		if !decouple {
+			if err := translatorctl.RemoveTranslatedApplication(ctx, i.Client, log, obj); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		log.V(5).Info("decoupling application", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := i.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+
+			if err := i.DecoupleTenant(obj, tenant); err != nil {
+				return err
+			}
+
+			meta.RemoveTranslatingFinalizers(obj)
+
+			return i.Client.Update(ctx, obj)
+		})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+
+	return nil
+}
+
+// Namespace for the application, either the argo namespace or a namespace of the tenant (if source namespaces are enabled)
+func (i *TenancyController) applicationNamespace(tenant *capsulev1beta2.Tenant, namespace string) (string, error) {
+	cfg := i.settings(tenant)
+	if namespace == "" || namespace == cfg.Argo.Namespace {
+		return cfg.Argo.Namespace, nil
+	}
+
+	if cfg.Argo.SourceNamespaces.Enabled && utils.ContainsString(tenant.Status.Namespaces, namespace) {
+		return namespace, nil
+	}
+
+	return "", fmt.Errorf("namespace %s is not allowed for applications of tenant %s", namespace, tenant.Name)
+}
+
+// Unique key for an Application or ApplicationSet
+func applicationKey(obj client.Object) string {
+	return fmt.Sprintf("%T/%s/%s", obj, obj.GetNamespace(), obj.GetName())
+}
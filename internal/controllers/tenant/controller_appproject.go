@@ -1,13 +1,10 @@
 package tenant
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"reflect"
 	"strings"
-	"text/template"
 
 	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/go-logr/logr"
@@ -18,13 +15,11 @@ import (
 	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
 	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	"github.com/peak-scale/capsule-argo-addon/internal/reflection"
-	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
 	"github.com/peak-scale/capsule-argo-addon/internal/utils"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
-	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
@@ -44,21 +39,37 @@ func (i *TenancyController) reconcileArgoProject(
 	appProject := &argocdv1alpha1.AppProject{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      meta.TenantProjectName(tenant),
-			Namespace: i.Settings.Get().Argo.Namespace,
+			Namespace: i.settings(tenant).Argo.Namespace,
 		},
 	}
 
 	log.V(7).Info("reconciling appproject", "appproject", appProject.Name)
 
 	// Fetch the current state of the AppProject
-	gerr := i.Client.Get(ctx, client.ObjectKey{Name: tenant.Name, Namespace: i.Settings.Get().Argo.Namespace}, appProject)
+	gerr := i.Client.Get(ctx, client.ObjectKeyFromObject(appProject), appProject)
 	if gerr != nil && !k8serrors.IsNotFound(gerr) {
 		return gerr
 	}
 
+	// Collect the tenants contributing to the AppProject
+	members, err := i.projectMembers(ctx, tenant, translators)
+	if err != nil {
+		return err
+	}
+	grouped := meta.TenantProjectGroup(tenant) != ""
+
+	// All known translators, to remove the config of translators no longer applied
+	knownTranslators := make(map[string]*configv1alpha1.ArgoTranslator, len(unmatchedTranslators)+len(translators))
+	for name, translator := range unmatchedTranslators {
+		knownTranslators[name] = translator
+	}
+	for _, translator := range translators {
+		knownTranslators[translator.Name] = translator
+	}
+
 	// Don't Force, When project already exists
 	// Check this before bootstraping any dependencies
-	if !meta.HasTenantOwnerReference(appProject, tenant) {
+	if !meta.HasTenantOwnerReference(appProject, tenant) && !projectOwnedByMembers(appProject, members) {
 		if !i.ForceTenant(tenant) && !k8serrors.IsNotFound(gerr) {
 			log.V(1).Info("appproject already present, not overriding", "appproject", appProject.Name)
 
@@ -77,65 +88,130 @@ func (i *TenancyController) reconcileArgoProject(
 	if err != nil {
 		return err
 	}
-	proxyService := i.Settings.Get().ProxyServiceString(tenant)
 
 	// Lifecycle Approject (If marked for deletion remove finalizers)
 	if !appProject.ObjectMeta.DeletionTimestamp.IsZero() || !tenant.ObjectMeta.DeletionTimestamp.IsZero() {
-		log.V(5).Info("removing finalizers for approject", "appproject", appProject.Name)
+		// Other tenants still contribute to the shared AppProject
+		leaving := grouped && len(members) > 0 && appProject.ObjectMeta.DeletionTimestamp.IsZero() && !k8serrors.IsNotFound(gerr)
+
+		if !leaving {
+			log.V(5).Info("removing finalizers for approject", "appproject", appProject.Name)
 
-		_, err = controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
+			_, err = controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
 
-			// Remove unmatched Translators based on finalizers
-			presentTranslators := meta.GetTranslatingFinalizers(appProject)
-			for _, translatorName := range presentTranslators {
-				if translator, found := unmatchedTranslators[translatorName]; found {
-					log.V(7).Info("removing translator config", "appproject", appProject.Name, "translator", translatorName)
+				// Remove unmatched Translators based on finalizers
+				presentTranslators := meta.GetTranslatingFinalizers(appProject)
+				for _, translatorName := range presentTranslators {
+					if translator, found := unmatchedTranslators[translatorName]; found {
+						log.V(7).Info("removing translator config", "appproject", appProject.Name, "translator", translatorName)
 
-					// Call RemoveTranslatorForTenant with the actual translator object
-					err := translatorctl.RemoveTranslatorForTenant(ctx, i.Client, log, translator, tenant, appProject, i.Settings)
-					if err != nil {
-						log.Error(err, "failed to remove translator", "translator", translatorName)
-						return err
+						// Call RemoveTranslatorForTenant with the actual translator object
+						err := translatorctl.RemoveTranslatorForTenant(ctx, i.Client, log, translator, tenant, appProject, i.Settings)
+						if err != nil {
+							log.Error(err, "failed to remove translator", "translator", translatorName)
+							return err
+						}
+					} else {
+						log.V(3).Info(
+							"removing no longer present translator finalizer",
+							"appproject", appProject.Name,
+							"translator", translatorName)
+						controllerutil.RemoveFinalizer(appProject, meta.TranslatorFinalizer(translatorName))
 					}
-				} else {
-					log.V(3).Info(
-						"removing no longer present translator finalizer",
-						"appproject", appProject.Name,
-						"translator", translatorName)
-					controllerutil.RemoveFinalizer(appProject, meta.TranslatorFinalizer(translatorName))
 				}
-			}
 
-			// Handle when the tenant is being deleted but the AppProject is decoupled
-			// In this case we remove the owner reference and the tenant tracking label so the Appproject can still exist
-			if !tenant.ObjectMeta.DeletionTimestamp.IsZero() {
-				if meta.TenantDecoupleProject(tenant) {
-					log.V(5).Info("decoupling appproject", "appproject", appProject.Name)
-					if err := i.DecoupleTenant(appProject, tenant); err != nil {
-						return err
+				// No tenant contributes to the AppProject anymore
+				for _, member := range meta.GetProjectMemberFinalizers(appProject) {
+					controllerutil.RemoveFinalizer(appProject, meta.ProjectMemberFinalizer(member))
+				}
+
+				// Handle when the tenant is being deleted but the AppProject is decoupled
+				// In this case we remove the owner reference and the tenant tracking label so the Appproject can still exist
+				if !tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+					if meta.TenantDecoupleProject(tenant) {
+						log.V(5).Info("decoupling appproject", "appproject", appProject.Name)
+						if err := i.DecoupleTenant(appProject, tenant); err != nil {
+							return err
+						}
 					}
 				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// Remove or decouple the applications and repositories of the tenant
+		if !tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+			if err := i.lifecycleArgoApplications(ctx, log, tenant, nil, meta.TenantDecoupleProject(tenant)); err != nil {
+				return err
 			}
 
-			return nil
-		})
-		if err != nil {
-			return err
+			if err := i.lifecycleArgoRepositories(ctx, log, tenant, nil, meta.TenantDecoupleProject(tenant)); err != nil {
+				return err
+			}
+
+			// Remove or decouple the tenant from the remote clusters
+			if _, err := i.reconcileArgoRemoteClusters(ctx, log, tenant, nil); err != nil {
+				return err
+			}
+		}
+
+		if leaving {
+			return i.leaveArgoProject(ctx, log, tenant, appProject, members, knownTranslators)
 		}
 
 		return nil
 	}
 
+	// Collect the tokens of the project roles
+	roleTokens, err := i.collectProjectRoleTokens(ctx, tenant, translators)
+	if err != nil {
+		return err
+	}
+
+	// Provision the tenant on the remote clusters
+	remoteDestinations, remoteErr := i.reconcileArgoRemoteClusters(ctx, log, tenant, translators)
+
 	// Lifecycle Approject (If no translators are present, remove the Approject)
 	if len(translators) == 0 {
+		// Remove or decouple the applications of the tenant
+		if err := i.reconcileArgoApplications(ctx, log, tenant, translators); err != nil {
+			return err
+		}
+
+		// Remove or decouple the repositories of the tenant
+		if err := i.lifecycleArgoRepositories(ctx, log, tenant, nil, meta.TenantDecoupleProject(tenant)); err != nil {
+			return err
+		}
+
+		// Remove the tokens of the project roles
+		if err := i.reflectProjectRoleTokens(ctx, log, tenant, roleTokens); err != nil {
+			return err
+		}
+
+		// Remove the local accounts of the tenant
+		if grouped {
+			if err := i.reconcileArgoAccounts(ctx, log, tenant, nil); err != nil {
+				return err
+			}
+		}
+
 		// Approject is already absent
 		if k8serrors.IsNotFound(gerr) {
-			return nil
+			return remoteErr
+		}
+
+		// Other tenants still contribute to the shared AppProject
+		if grouped && len(members) > 0 {
+			return errors.Join(i.leaveArgoProject(ctx, log, tenant, appProject, members, knownTranslators), remoteErr)
 		}
 
 		// Delete the AppProject when it's not decoupled
 		if !meta.TenantDecoupleProject(tenant) {
-			return i.Client.Delete(ctx, appProject)
+			return errors.Join(i.Client.Delete(ctx, appProject), remoteErr)
 		} else {
 			log.V(5).Info("decoupling appproject", "appproject", appProject.Name)
 			if err := i.DecoupleTenant(appProject, tenant); err != nil {
@@ -144,40 +220,137 @@ func (i *TenancyController) reconcileArgoProject(
 		}
 	}
 
+	// Issue the tokens of the project roles
+	issueErr := i.issueProjectRoleTokens(ctx, log, tenant, appProject, roleTokens)
+
 	log.Info("reconcile appproject", "appproject", appProject.Name)
 
 	_, err = controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
-		// Prepare metadata
-		appProject.ObjectMeta.Labels = meta.TranslatorTrackingLabels(tenant)
-		if appProject.ObjectMeta.Annotations == nil {
-			appProject.ObjectMeta.Annotations = make(map[string]string)
+		if err := i.translateArgoProject(ctx, log, appProject, tenant, members, knownTranslators, roleTokens, remoteDestinations); err != nil {
+			return err
 		}
 
-		appliedTranslatorsSet := make(map[string]struct{})
-		translatedSpec := &argocdv1alpha1.AppProjectSpec{}
-		for _, translator := range translators {
-			// Get Approject Config with templating
-			translatorCfg, err := translator.Spec.ProjectSettings.GetConfig(
-				tpl.ConfigContext(proxyService, translator, i.Settings.Get(), tenant), tpl.ExtraFuncMap())
+		stampInputHash(ctx, appProject)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove the obsolete tokens of the project roles
+	err = i.reflectProjectRoleTokens(ctx, log, tenant, roleTokens)
+	if err != nil {
+		return err
+	}
+
+	// Reflect Argo RBAC
+	err = i.reflectArgoRBAC(ctx, log, tenant, members)
+	if err != nil {
+		return err
+	}
+
+	log.V(5).Info("reflected argo permissions", "appproject", appProject.Name, "configmap", i.settings(tenant).Argo.RBACConfigMap, "namespace", i.settings(tenant).Argo.Namespace, "key", argo.ArgoPolicyName(tenant))
+
+	// Reconcile Accounts
+	err = i.reconcileArgoAccounts(ctx, log, tenant, translators)
+	if err != nil {
+		return err
+	}
+
+	// Reconcile Applications
+	err = i.reconcileArgoApplications(ctx, log, tenant, translators)
+	if err != nil {
+		return err
+	}
+
+	// Reflect Repositories
+	err = i.reconcileArgoRepositories(ctx, log, tenant, appProject)
+	if err != nil {
+		return err
+	}
+
+	// Reflect Source Namespaces
+	i.requestSourceNamespaces()
+
+	return errors.Join(remoteErr, issueErr)
+}
+
+// Removes the contributions of the tenant from a shared AppProject, the AppProject is translated
+// from the remaining members.
+func (i *TenancyController) leaveArgoProject(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	appProject *argocdv1alpha1.AppProject,
+	members []projectMember,
+	knownTranslators map[string]*configv1alpha1.ArgoTranslator,
+) error {
+	log.V(5).Info("leaving shared appproject", "appproject", appProject.Name, "members", len(members))
+
+	_, err := controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
+		return i.translateArgoProject(ctx, log, appProject, tenant, members, knownTranslators, &projectRoleTokens{current: map[string]string{}}, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	return i.reflectArgoRBAC(ctx, log, tenant, members)
+}
+
+// Translates the AppProject from the translators of all members. The remote destinations and role tokens are
+// those of the reconciled tenant. Contributions of tenants which are no longer members are removed.
+//
+//nolint:gocyclo
+func (i *TenancyController) translateArgoProject(
+	ctx context.Context,
+	log logr.Logger,
+	appProject *argocdv1alpha1.AppProject,
+	tenant *capsulev1beta2.Tenant,
+	members []projectMember,
+	knownTranslators map[string]*configv1alpha1.ArgoTranslator,
+	roleTokens *projectRoleTokens,
+	remoteDestinations []argocdv1alpha1.ApplicationDestination,
+) error {
+	grouped := meta.TenantProjectGroup(tenant) != ""
+
+	// Shared AppProjects are tracked by their first member
+	tracked := tenant
+	if grouped && len(members) > 0 {
+		tracked = members[0].tenant
+	}
+
+	// Prepare metadata
+	appProject.ObjectMeta.Labels = meta.TranslatorTrackingLabels(tracked)
+	if appProject.ObjectMeta.Annotations == nil {
+		appProject.ObjectMeta.Annotations = make(map[string]string)
+	}
+
+	// Roles before the translation (holds the issued tokens)
+	existingRoles := appProject.Spec.Roles
+
+	memberNames := make([]string, 0, len(members))
+	appliedTranslatorsSet := make(map[string]struct{})
+	translatedSpec := &argocdv1alpha1.AppProjectSpec{}
+	for _, member := range members {
+		memberNames = append(memberNames, member.tenant.Name)
+
+		for _, translator := range member.translators {
+			renderer, err := i.translatorRenderer(ctx, member.tenant, translator)
 			if err != nil {
 				return err
 			}
 
-			cfg1, cfg2, err := translator.Spec.ProjectSettings.GetConfigs(
-				tpl.ConfigContext(proxyService, translator, i.Settings.Get(), tenant), tpl.ExtraFuncMap())
+			// Get Approject Config with templating
+			translatorCfg, err := renderer.ProjectConfig()
 			if err != nil {
 				return err
 			}
-			log.V(10).Info(
-				"translator-config",
-				"translator", translator.Name,
-				"appproject", appProject.Name,
-				"structured", cfg1,
-				"templated", cfg2)
 
 			log.V(7).Info(
 				"translator-config",
 				"translator", translator.Name,
+				"tenant", member.tenant.Name,
 				"appproject", appProject.Name,
 				"config", translatorCfg.ProjectSpec)
 
@@ -208,147 +381,210 @@ func (i *TenancyController) reconcileArgoProject(
 
 			log.V(7).Info("reconciled", "translator", translator.Name, "appproject", appProject.Name)
 		}
+	}
 
-		// Remove unmatched Translators based on finalizers
-		allTranslators := meta.GetTranslatingFinalizers(appProject)
-		for _, translatorName := range allTranslators {
-			if _, exists := appliedTranslatorsSet[translatorName]; !exists {
-				if translator, found := unmatchedTranslators[translatorName]; found {
-					log.V(7).Info("removing translator config", "appproject", appProject.Name, "translator", translatorName)
-
-					// Call RemoveTranslatorForTenant with the actual translator object
-					err := translatorctl.RemoveTranslatorForTenant(ctx, i.Client, log, translator, tenant, appProject, i.Settings)
-					if err != nil {
-						log.Error(err, "failed to remove translator", "translator", translatorName)
-						return err
-					}
+	// Remove unmatched Translators based on finalizers
+	allTranslators := meta.GetTranslatingFinalizers(appProject)
+	for _, translatorName := range allTranslators {
+		if _, exists := appliedTranslatorsSet[translatorName]; !exists {
+			if translator, found := knownTranslators[translatorName]; found {
+				log.V(7).Info("removing translator config", "appproject", appProject.Name, "translator", translatorName)
+
+				// Call RemoveTranslatorForTenant with the actual translator object
+				err := translatorctl.RemoveTranslatorForTenant(ctx, i.Client, log, translator, tenant, appProject, i.Settings)
+				if err != nil {
+					log.Error(err, "failed to remove translator", "translator", translatorName)
+					return err
 				}
-
-				log.V(7).Info(
-					"translator not present",
-					"appproject", appProject.Name,
-					"translator", translatorName)
 			}
+
+			log.V(7).Info(
+				"translator not present",
+				"appproject", appProject.Name,
+				"translator", translatorName)
 		}
+	}
 
-		log.V(7).Info("combined translators config", "appproject", appProject.Name, "config", translatedSpec)
+	log.V(7).Info("combined translators config", "appproject", appProject.Name, "config", translatedSpec)
 
-		//// Merge the translatedSpec into the appProject.Spec
-		if meta.TenantReadOnly(tenant) {
-			log.V(5).Info("overwriting spec", "appproject", appProject.Name)
-			// Overwrite translatedSpec into the appProject.Spec
-			appProject.Spec = *translatedSpec
-		} else {
-			log.V(5).Info("merging spec")
-			// Merge with current Spec
-			err = reflection.Merge(&appProject.Spec, translatedSpec)
-			if err != nil {
-				return fmt.Errorf("failed to merge project spec: %w", err)
-			}
+	//// Merge the translatedSpec into the appProject.Spec
+	if meta.TenantReadOnly(tenant) {
+		log.V(5).Info("overwriting spec", "appproject", appProject.Name)
+		// Overwrite translatedSpec into the appProject.Spec
+		appProject.Spec = *translatedSpec
+	} else {
+		log.V(5).Info("merging spec")
+		// Merge with current Spec
+		err := reflection.Merge(&appProject.Spec, translatedSpec)
+		if err != nil {
+			return fmt.Errorf("failed to merge project spec: %w", err)
 		}
+	}
 
-		// Register the Tenant as a Destination
+	// Register the Tenants as a Destination
+	for _, member := range members {
 		proxyDestination := argocdv1alpha1.ApplicationDestination{
-			Name:      tenant.Name,
-			Server:    proxyService,
+			Name:      member.tenant.Name,
+			Server:    i.settings(member.tenant).ProxyServiceString(member.tenant),
 			Namespace: "*",
 		}
 
 		switch {
-		// Add the proxy destination when the proxy is enabled and there are translators
-		case i.Settings.Get().Proxy.Enabled && len(translators) > 0:
+		// Add the proxy destination when the proxy is enabled
+		case i.settings(member.tenant).Proxy.Enabled:
 			if !argo.ProjectHasDestination(appProject, proxyDestination) {
-				log.V(5).Info("adding proxy destination", "appproject", appProject.Name)
+				log.V(5).Info("adding proxy destination", "appproject", appProject.Name, "tenant", member.tenant.Name)
 				appProject.Spec.Destinations = append(appProject.Spec.Destinations, proxyDestination)
 			}
 		// Remove the proxy destination
 		default:
 			if argo.ProjectHasDestination(appProject, proxyDestination) {
-				log.V(5).Info("removing proxy destination", "appproject", appProject.Name)
+				log.V(5).Info("removing proxy destination", "appproject", appProject.Name, "tenant", member.tenant.Name)
 				argo.RemoveProjectDestination(appProject, proxyDestination)
 			}
 		}
+	}
+
+	// Remove the proxy destination, when the tenant does not contribute
+	if !utils.ContainsString(memberNames, tenant.Name) {
+		proxyDestination := argocdv1alpha1.ApplicationDestination{
+			Name:      tenant.Name,
+			Server:    i.settings(tenant).ProxyServiceString(tenant),
+			Namespace: "*",
+		}
+
+		if argo.ProjectHasDestination(appProject, proxyDestination) {
+			log.V(5).Info("removing proxy destination", "appproject", appProject.Name)
+			argo.RemoveProjectDestination(appProject, proxyDestination)
+		}
+	}
+
+	// Register the remote clusters as Destinations
+	meta.SetAnnotationList(appProject, meta.ManagedDestinationsAnnotation(tenant.Name), argo.ReplaceManagedDestinations(
+		appProject,
+		meta.GetAnnotationList(appProject, meta.ManagedDestinationsAnnotation(tenant.Name)),
+		remoteDestinations,
+	))
+
+	// Reflect the tenant namespaces as source namespaces
+	if err := i.reflectProjectSourceNamespaces(log, appProject, members); err != nil {
+		return err
+	}
+
+	// Reflect the project roles
+	if err := i.reflectProjectRoles(log, appProject, existingRoles, members, roleTokens); err != nil {
+		return err
+	}
+
+	// Deny syncs to the namespaces of cordoned tenants
+	for _, member := range members {
+		if err := reflectProjectSyncWindow(log, appProject, member.tenant.Name, i.cordonSyncWindow(member.tenant)); err != nil {
+			return err
+		}
+	}
 
-		// Couple oder Decouple the AppProject
+	if !utils.ContainsString(memberNames, tenant.Name) {
+		if err := reflectProjectSyncWindow(log, appProject, tenant.Name, nil); err != nil {
+			return err
+		}
+	}
 
+	// Couple oder Decouple the AppProject
+	if !grouped {
 		// Check if tenant is being deleted (Remove owner reference)
 		log.V(5).Info("ensuring ownerreference", "appproject", appProject.Name)
 		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), appProject, tenant)
-	})
-	if err != nil {
-		return err
 	}
 
-	// Reflect Argo RBAC
-	err = i.reflectArgoRBAC(ctx, log, tenant, translators)
-	if err != nil {
-		return err
+	// Remove the contributions of tenants which are no longer members
+	for _, former := range meta.GetProjectMemberFinalizers(appProject) {
+		if utils.ContainsString(memberNames, former) {
+			continue
+		}
+
+		log.V(5).Info("removing former member", "appproject", appProject.Name, "tenant", former)
+
+		argo.ReplaceManagedDestinations(appProject, []string{former}, nil)
+		argo.ReplaceManagedDestinations(appProject, meta.GetAnnotationList(appProject, meta.ManagedDestinationsAnnotation(former)), nil)
+		meta.SetAnnotationList(appProject, meta.ManagedDestinationsAnnotation(former), nil)
+
+		if err := reflectProjectSyncWindow(log, appProject, former, nil); err != nil {
+			return err
+		}
+
+		meta.RemoveTenantOwnerReferencesByName(appProject, former)
+		controllerutil.RemoveFinalizer(appProject, meta.ProjectMemberFinalizer(former))
+	}
+
+	// The leaving tenant no longer owns the AppProject
+	if !utils.ContainsString(memberNames, tenant.Name) {
+		meta.RemoveTenantOwnerReferencesByName(appProject, tenant.Name)
+	}
+
+	// Every member owns the AppProject
+	for _, member := range members {
+		controllerutil.AddFinalizer(appProject, meta.ProjectMemberFinalizer(member.tenant.Name))
+
+		if err := meta.AddSharedTenantOwnerReference(i.Client.Scheme(), appProject, member.tenant); err != nil {
+			return err
+		}
 	}
 
-	log.V(5).Info("reflected argo permissions", "appproject", appProject.Name, "configmap", i.Settings.Get().Argo.RBACConfigMap, "namespace", i.Settings.Get().Argo.Namespace, "key", argo.ArgoPolicyName(tenant))
 	return nil
 }
 
-// Applies RBAC to the ArgoCD RBAC configmap in
+// Applies RBAC of all members to the ArgoCD RBAC configmap
 func (i *TenancyController) reflectArgoRBAC(
 	ctx context.Context,
 	log logr.Logger,
 	tenant *capsulev1beta2.Tenant,
-	translators []*v1alpha1.ArgoTranslator,
+	members []projectMember,
 ) (err error) {
-	// Initialize target configmap
-	configmap := &corev1.ConfigMap{}
-	err = i.Client.Get(ctx, client.ObjectKey{
-		Name:      i.Settings.Get().Argo.RBACConfigMap,
-		Namespace: i.Settings.Get().Argo.Namespace}, configmap)
-	if err != nil {
-		return err
-	}
-
-	// Empty Translators, attempt to remove the tenant from the configmap
-	if len(translators) == 0 {
+	// No members, attempt to remove the tenant from the policies
+	if len(members) == 0 {
 		log.V(7).Info("removing argo rbac", "tenant", tenant.Name)
-		if _, ok := configmap.Data[argo.ArgoPolicyName(tenant)]; ok {
-			_, err = controllerutil.CreateOrUpdate(ctx, i.Client, configmap, func() error {
-				delete(configmap.Data, argo.ArgoPolicyName(tenant))
 
-				return nil
-			})
-			return err
-		}
+		return i.reflectArgoPolicy(ctx, log, tenant, "")
 	}
 
 	// Generate Argo RBAC permissions
-	rbacCSV, err := i.reflectArgoCSV(log, tenant, translators)
-	if err != nil {
-		return err
+	var sb strings.Builder
+	for _, member := range members {
+		rbacCSV, err := i.reflectArgoCSV(ctx, log, member.tenant, member.translators)
+		if err != nil {
+			return err
+		}
+
+		sb.WriteString(rbacCSV)
 	}
 
-	log.V(7).Info("resulting argo CSV", "tenant", tenant.Name, "csv", rbacCSV)
+	log.V(7).Info("resulting argo CSV", "tenant", tenant.Name, "csv", sb.String())
 
-	// Apply the CSV to the configmap
-	if !reflect.DeepEqual(configmap.Data[argo.ArgoPolicyName(tenant)], rbacCSV) {
-		err = retry.RetryOnConflict(retry.DefaultBackoff, func() (conflictErr error) {
-			_, conflictErr = controllerutil.CreateOrUpdate(ctx, i.Client, configmap, func() error {
-				configmap.Data[argo.ArgoPolicyName(tenant)] = rbacCSV
+	return i.reflectArgoPolicy(ctx, log, tenant, sb.String())
+}
 
-				return nil
-			})
+// Renders the custom policy of the translator for the tenant
+func (i *TenancyController) renderCustomPolicy(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	translator *v1alpha1.ArgoTranslator,
+) (string, error) {
+	renderer, err := i.translatorRenderer(ctx, tenant, translator)
+	if err != nil {
+		return "", err
+	}
 
-			return
-		})
-		if err != nil {
-			return err
-		}
-	} else {
-		log.V(7).Info("csv already updated", "tenant", tenant.Name)
+	policy, err := renderer.CustomPolicy()
+	if err != nil {
+		return "", fmt.Errorf("failed to render custom policy of translator %s: %w", translator.Name, err)
 	}
 
-	return nil
+	return policy, nil
 }
 
 // Creates CSV file to be applied to the argo configmap
 func (i *TenancyController) reflectArgoCSV(
+	ctx context.Context,
 	log logr.Logger,
 	tenant *capsulev1beta2.Tenant,
 	translators []*v1alpha1.ArgoTranslator,
@@ -359,6 +595,9 @@ func (i *TenancyController) reflectArgoCSV(
 	roles := utils.GetClusterRolePermissions(tenant)
 	log.V(10).Info("extracted roles for tenant", "tenant", tenant.Name, "roles", roles)
 
+	// Owners are demoted while the tenant is cordoned
+	demote := i.cordonReadOnly(tenant)
+
 	// Add Default Policies for App-Project
 	for _, dlts := range argo.DefaultPolicies(tenant, i.provisionProxyService(tenant)) {
 		sb.WriteString(dlts)
@@ -372,13 +611,15 @@ func (i *TenancyController) reflectArgoCSV(
 		// Translate Policies
 		for _, argopolicy := range translator.Spec.ProjectRoles {
 			// Role-Name
-			roleName := argo.TenantPolicy(tenant, argopolicy.Name)
-
-			// Create Argo Policy
-			for _, pol := range argopolicy.Policies {
-				policy := argo.PolicyString(roleName, tenant.Name, pol)
-				sb.WriteString(policy)
-				log.V(10).Info("generated policy", "translator", translator.Name, "policy", policy)
+			roleName := translatorRoleName(tenant, translator, argopolicy.Name)
+
+			// Create Argo Policy (Policies of project roles are part of the appproject)
+			if !argopolicy.IsProjectScoped() {
+				for _, pol := range argopolicy.Policies {
+					policy := argo.PolicyString(roleName, meta.TenantProjectName(tenant), pol)
+					sb.WriteString(policy)
+					log.V(10).Info("generated policy", "translator", translator.Name, "policy", policy)
+				}
 			}
 
 			log.V(7).Info("generating bindings")
@@ -392,11 +633,14 @@ func (i *TenancyController) reflectArgoCSV(
 					log.V(10).Info("found subjects for clusterRole", "translator", translator.Name, "clusterrole", clusterRole, "subjects", val)
 
 					for _, subject := range val {
-						sb.WriteString(argo.BindingString(subject, roleName))
+						// Groups of project roles are assigned on the appproject
+						if !argopolicy.IsProjectScoped() || subject.Kind != rbacv1.GroupKind {
+							sb.WriteString(argo.BindingString(subject, roleName))
+						}
 
 						// Assign Access to the tenant
 						sb.WriteString(argo.BindingString(subject, argo.DefaultPolicyReadOnly(tenant)))
-						if argopolicy.Owner {
+						if argopolicy.Owner && !demote {
 							sb.WriteString(argo.BindingString(subject, argo.DefaultPolicyOwner(tenant)))
 						}
 					}
@@ -406,31 +650,38 @@ func (i *TenancyController) reflectArgoCSV(
 			}
 		}
 
+		// Assign Accounts
+		for _, account := range translator.Spec.Accounts {
+			subject := rbacv1.Subject{Kind: rbacv1.UserKind, Name: argo.AccountName(tenant, account.Name)}
+			log.V(7).Info("generating bindings for account", "translator", translator.Name, "account", subject.Name)
+
+			sb.WriteString(argo.BindingString(subject, argo.DefaultPolicyReadOnly(tenant)))
+			if account.Owner && !demote {
+				sb.WriteString(argo.BindingString(subject, argo.DefaultPolicyOwner(tenant)))
+			}
+
+			for _, role := range account.Roles {
+				sb.WriteString(argo.BindingString(subject, translatorRoleName(tenant, translator, role)))
+			}
+		}
+
 		// Update Custom-Policies
 		if translator.Spec.CustomPolicy != "" {
 			log.V(7).Info("appending custom policy from translator", "translator", translator.Name)
 
+			customPolicy, err := i.renderCustomPolicy(ctx, tenant, translator)
+			if err != nil {
+				return "", err
+			}
+
 			sb.WriteString("\n")
-			sb.WriteString(translator.Spec.CustomPolicy)
+			sb.WriteString(customPolicy)
 			sb.WriteString("\n")
 		}
 	}
 
-	// Template CSV
-	log.V(7).Info("templating argo csv")
-
-	ArgoCSVTemplate := sb.String()
-	tmpl, err := template.New("rbac").Funcs(tpl.ExtraFuncMap()).Parse(ArgoCSVTemplate)
-	if err != nil {
-		return "", err
-	}
-
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, nil); err != nil {
-		return "", err
-	}
-
-	finalCSV := buf.String()
+	// Custom policies are templated per translator
+	finalCSV := sb.String()
 
 	if err := argo.ValidateCSV(finalCSV); err != nil {
 		return "", errors.New("invalid argo csv: " + err.Error())
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
 	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
 	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
@@ -13,7 +14,6 @@ import (
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
@@ -29,18 +29,18 @@ func (i *TenancyController) reconcileArgoCluster(
 	serverSecret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      tenant.Name,
-			Namespace: i.Settings.Get().Argo.Namespace,
+			Namespace: i.settings(tenant).Argo.Namespace,
 		},
 		Type: corev1.SecretTypeOpaque,
 	}
 
 	// Get Cluster-Secret
-	err := i.Client.Get(ctx, client.ObjectKey{Name: serverSecret.Name, Namespace: serverSecret.Namespace}, serverSecret)
+	err := i.getManagedObject(ctx, serverSecret)
 	if err != nil && !k8serrors.IsNotFound(err) {
 		return err
 	}
 
-	log.V(7).Info("reconciling cluster", "secret", tenant.Name, "namespace", i.Settings.Get().Argo.Namespace)
+	log.V(7).Info("reconciling cluster", "secret", tenant.Name, "namespace", i.settings(tenant).Argo.Namespace)
 
 	// Handle the Proxy-Service for the tenant
 	cluster, _ := i.proxyService(ctx, log, tenant)
@@ -74,7 +74,7 @@ func (i *TenancyController) reconcileArgoCluster(
 			log.V(5).Info(
 				"cluster secret already present, not overriding",
 				"secret", tenant.Name,
-				"namespace", i.Settings.Get().Argo.Namespace)
+				"namespace", i.settings(tenant).Argo.Namespace)
 
 			return ccaerrrors.NewObjectAlreadyExistsError(serverSecret)
 		}
@@ -95,10 +95,10 @@ func (i *TenancyController) reconcileArgoCluster(
 	}
 
 	// Dynamic
-	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, serverSecret, func() error {
+	_, err = i.createOrUpdateManagedObject(ctx, serverSecret, func() error {
 		// Update secret metadata
 		labels := meta.WithTranslatorTrackingLabels(serverSecret, tenant)
-		labels["argocd.argoproj.io/secret-type"] = "cluster"
+		labels[argo.SecretTypeLabel] = argo.SecretTypeCluster
 		serverSecret.SetLabels(labels)
 
 		extraData := map[string]interface{}{
@@ -115,10 +115,11 @@ func (i *TenancyController) reconcileArgoCluster(
 
 		serverSecret.StringData = map[string]string{
 			"name":    tenant.Name,
-			"project": tenant.Name,
+			"project": meta.TenantProjectName(tenant),
 			"server":  cluster,
 			"config":  string(jsonData),
 		}
+		stampInputHash(ctx, serverSecret)
 
 		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), serverSecret, tenant)
 	})
@@ -140,17 +141,17 @@ func (i *TenancyController) proxyService(
 	service := &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      tenant.Name,
-			Namespace: i.Settings.Get().Proxy.CapsuleProxyServiceNamespace,
+			Namespace: i.settings(tenant).Proxy.CapsuleProxyServiceNamespace,
 		},
 	}
 
 	log.V(7).Info(
 		"reconciling service",
 		"service", replicatedName,
-		"namespace", i.Settings.Get().Proxy.CapsuleProxyServiceNamespace)
+		"namespace", i.settings(tenant).Proxy.CapsuleProxyServiceNamespace)
 
 	// Get Cluster-Secret
-	err = i.Client.Get(ctx, client.ObjectKey{Name: service.Name, Namespace: service.Namespace}, service)
+	err = i.getManagedObject(ctx, service)
 	if err != nil && !k8serrors.IsNotFound(err) {
 		return "", err
 	}
@@ -195,7 +196,7 @@ func (i *TenancyController) proxyService(
 		}
 
 		// Return proxy service url
-		//if !i.Settings.Get().Proxy.Enabled {
+		//if !i.settings(tenant).Proxy.Enabled {
 		//	return i.proxyServiceName(tenant)
 		//}
 
@@ -206,18 +207,19 @@ func (i *TenancyController) proxyService(
 	// Get Referenced Error
 	proxySvc := &corev1.Service{}
 	err = i.Client.Get(ctx, types.NamespacedName{
-		Namespace: i.Settings.Get().Proxy.CapsuleProxyServiceNamespace,
-		Name:      i.Settings.Get().Proxy.CapsuleProxyServiceName,
+		Namespace: i.settings(tenant).Proxy.CapsuleProxyServiceNamespace,
+		Name:      i.settings(tenant).Proxy.CapsuleProxyServiceName,
 	}, proxySvc)
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve proxy service: %w", err)
 	}
 
 	// Replicate a proxy service for the tenant
-	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, service, func() error {
+	_, err = i.createOrUpdateManagedObject(ctx, service, func() error {
 		service.Labels = meta.TranslatorTrackingLabels(tenant)
 		service.Spec.Ports = proxySvc.Spec.Ports
 		service.Spec.Selector = proxySvc.Spec.Selector
+		stampInputHash(ctx, service)
 
 		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), service, tenant)
 	})
@@ -228,5 +230,5 @@ func (i *TenancyController) proxyService(
 	i.Log.V(5).Info("Proxy Service created", "name", tenant.Name)
 
 	// Returns the proxy service url
-	return i.Settings.Get().ProxyServiceString(tenant), nil
+	return i.settings(tenant).ProxyServiceString(tenant), nil
 }
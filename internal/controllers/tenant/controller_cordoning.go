@@ -0,0 +1,66 @@
+package tenant
+
+import (
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+)
+
+// Sync window denying syncs to the namespaces of the tenant, nil if the tenant is not cordoned
+func (i *TenancyController) cordonSyncWindow(tenant *capsulev1beta2.Tenant) *argocdv1alpha1.SyncWindow {
+	if !utils.TenantCordoned(tenant) || !i.settings(tenant).Cordoning.SyncWindow || !tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	return argo.DenySyncWindow(tenant.Status.Namespaces)
+}
+
+// Owners of the tenant are demoted to read-only, while the tenant is cordoned
+func (i *TenancyController) cordonReadOnly(tenant *capsulev1beta2.Tenant) bool {
+	return utils.TenantCordoned(tenant) && i.settings(tenant).Cordoning.ReadOnly
+}
+
+// Replaces the sync window injected for a tenant with the desired sync window. Only the sync window
+// injected by the controller is removed.
+func reflectProjectSyncWindow(
+	log logr.Logger,
+	appProject *argocdv1alpha1.AppProject,
+	tenant string,
+	desired *argocdv1alpha1.SyncWindow,
+) error {
+	key := meta.ManagedSyncWindowAnnotation(tenant)
+
+	previous, err := argo.ParseSyncWindow(appProject.GetAnnotations()[key])
+	if err != nil {
+		log.V(3).Info("ignoring invalid injected sync window", "appproject", appProject.Name, "tenant", tenant)
+
+		previous = nil
+	}
+
+	injected := argo.ReplaceSyncWindow(appProject, previous, desired)
+
+	raw, err := argo.FormatSyncWindow(injected)
+	if err != nil {
+		return err
+	}
+
+	annotations := appProject.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if raw == "" {
+		delete(annotations, key)
+	} else {
+		log.V(7).Info("injected sync window", "appproject", appProject.Name, "tenant", tenant)
+
+		annotations[key] = raw
+	}
+
+	appProject.SetAnnotations(annotations)
+
+	return nil
+}
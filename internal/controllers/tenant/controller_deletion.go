@@ -0,0 +1,185 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Interval to check the Applications again, while the deletion of a tenant waits for them
+const applicationsPendingRequeue = 10 * time.Second
+
+// Applies the deletion policy to the Applications in the appproject of a deleted tenant. Returns an ApplicationsPending
+// error while Applications (or ApplicationSets) remain in the appproject. Applications provisioned by translators
+// are handled with the tenant.
+func (i *TenancyController) reconcileArgoDeletionPolicy(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+) error {
+	// The appproject is kept for decoupled tenants
+	if !controllerutil.ContainsFinalizer(tenant, meta.Finalizer()) || meta.TenantDecoupleProject(tenant) {
+		return nil
+	}
+
+	// Other tenants still share the appproject
+	members, err := i.projectMembers(ctx, tenant, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(members) > 0 {
+		return nil
+	}
+
+	cfg := i.settings(tenant)
+	project := meta.TenantProjectName(tenant)
+	policy := cfg.TenantDeletionPolicy(tenant)
+
+	// The appproject is removed regardless of the Applications
+	if policy == v1alpha1.DeletionPolicyIgnore {
+		return nil
+	}
+
+	applications, err := i.projectApplications(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	applicationSets, err := i.projectApplicationSets(ctx, project)
+	if err != nil {
+		return err
+	}
+
+	var pending []client.Object
+	for _, application := range applications {
+		if !meta.HasTenantOwnerReference(application, tenant) {
+			pending = append(pending, application)
+		}
+	}
+
+	for _, applicationSet := range applicationSets {
+		if !meta.HasTenantOwnerReference(applicationSet, tenant) {
+			pending = append(pending, applicationSet)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	log.V(5).Info("applying deletion policy", "appproject", project, "policy", policy, "applications", len(pending))
+
+	switch policy {
+	case v1alpha1.DeletionPolicyOrphan:
+		quarantine := cfg.Deletion.QuarantineProject
+		if err := i.validateQuarantineProject(ctx, tenant, quarantine); err != nil {
+			return err
+		}
+
+		log.V(3).Info("moving applications to quarantine project", "appproject", project, "quarantine", quarantine)
+
+		for _, obj := range pending {
+			if err := i.quarantineArgoApplication(ctx, log, obj, quarantine); err != nil {
+				return err
+			}
+		}
+	case v1alpha1.DeletionPolicyCascade:
+		for _, obj := range pending {
+			if err := i.cascadeArgoApplication(ctx, log, obj); err != nil {
+				return err
+			}
+		}
+	}
+
+	return ccaerrrors.NewApplicationsPendingError(project, string(policy), len(pending))
+}
+
+// Validates the quarantine project for the policy "Orphan". The quarantine project must exist and must not allow
+// wildcard destinations, otherwise the Applications of the tenant would gain access to other namespaces
+func (i *TenancyController) validateQuarantineProject(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	quarantine string,
+) error {
+	if quarantine == "" {
+		return fmt.Errorf("no quarantine project configured for the deletion policy %s", v1alpha1.DeletionPolicyOrphan)
+	}
+
+	appProject := &argocdv1alpha1.AppProject{}
+	if err := i.Client.Get(ctx, client.ObjectKey{Name: quarantine, Namespace: i.settings(tenant).Argo.Namespace}, appProject); err != nil {
+		return fmt.Errorf("failed to get quarantine project %s: %w", quarantine, err)
+	}
+
+	for _, destination := range appProject.Spec.Destinations {
+		if strings.Contains(destination.Server, "*") || strings.Contains(destination.Name, "*") || strings.Contains(destination.Namespace, "*") {
+			return fmt.Errorf("quarantine project %s allows wildcard destinations", quarantine)
+		}
+	}
+
+	return nil
+}
+
+// Moves an Application (or the Applications generated by an ApplicationSet) to the quarantine project
+func (i *TenancyController) quarantineArgoApplication(
+	ctx context.Context,
+	log logr.Logger,
+	obj client.Object,
+	quarantine string,
+) error {
+	log.V(5).Info("quarantining application", "name", obj.GetName(), "namespace", obj.GetNamespace(), "appproject", quarantine)
+
+	_, err := controllerutil.CreateOrPatch(ctx, i.Client, obj, func() error {
+		switch application := obj.(type) {
+		case *argocdv1alpha1.Application:
+			application.Spec.Project = quarantine
+		case *argocdv1alpha1.ApplicationSet:
+			application.Spec.Template.Spec.Project = quarantine
+		}
+
+		return nil
+	})
+
+	return client.IgnoreNotFound(err)
+}
+
+// Deletes an Application including its resources (or an ApplicationSet)
+func (i *TenancyController) cascadeArgoApplication(
+	ctx context.Context,
+	log logr.Logger,
+	obj client.Object,
+) error {
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+
+	if application, ok := obj.(*argocdv1alpha1.Application); ok {
+		_, err := controllerutil.CreateOrPatch(ctx, i.Client, application, func() error {
+			controllerutil.AddFinalizer(application, argocdv1alpha1.ResourcesFinalizerName)
+
+			return nil
+		})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+
+	log.V(5).Info("deleting application", "name", obj.GetName(), "namespace", obj.GetNamespace())
+
+	if err := i.Client.Delete(ctx, obj); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,298 @@
+package tenant
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Resync interval of the tenants, when none is configured
+const defaultResyncInterval = time.Hour
+
+type inputHashKey struct{}
+
+// Input hash of the current reconciliation, held back when an asset is still pending
+type inputHashState struct {
+	hash string
+	held atomic.Bool
+}
+
+// Inputs of the reconciliation of a tenant
+type reconcileInputs struct {
+	Generation   int64
+	State        string
+	Labels       map[string]string
+	Annotations  map[string]string
+	Namespaces   map[string]map[string]string
+	Translators  []objectInput
+	Unmatched    []objectInput
+	Libraries    []objectInput
+	Values       string
+	Repositories []objectInput
+	ProxyService string
+	Config       *v1alpha1.ArgoAddonSpec
+	Resync       int64
+}
+
+type objectInput struct {
+	Name       string
+	Generation int64             `json:",omitempty"`
+	Version    string            `json:",omitempty"`
+	Labels     map[string]string `json:",omitempty"`
+}
+
+// Hash of the inputs of the tenant. The hash is empty when the reconciliation can not be skipped, because the
+// assets depend on more than the collected inputs (deletion, project groups, templates using lookup, tokens which
+// expire, remote clusters or assets which are not stamped with the hash)
+func (i *TenancyController) inputHash(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+	unmatched []*v1alpha1.ArgoTranslator,
+) (string, error) {
+	if !tenant.ObjectMeta.DeletionTimestamp.IsZero() || len(translators) == 0 || meta.TenantProjectGroup(tenant) != "" {
+		return "", nil
+	}
+
+	settings := i.settings(tenant)
+
+	// Remote clusters are not watched, the provisioning may still be pending
+	if len(settings.Clusters) > 0 && i.provisionProxyService(tenant) {
+		return "", nil
+	}
+
+	inputs := reconcileInputs{
+		Generation:  tenant.Generation,
+		State:       string(tenant.Status.State),
+		Labels:      tenant.GetLabels(),
+		Annotations: tenant.GetAnnotations(),
+		Namespaces:  make(map[string]map[string]string, len(tenant.Status.Namespaces)),
+		Config:      settings,
+		Resync:      resyncBucket(tenant, i.options.Resync(defaultResyncInterval)),
+	}
+
+	for _, translator := range translators {
+		if translatorUnstamped(translator) {
+			return "", nil
+		}
+
+		libraries, err := tpl.TranslatorLibraries(ctx, i.Client, translator)
+		if err != nil {
+			return "", err
+		}
+
+		if !tpl.TranslatorPure(translator, libraries) {
+			return "", nil
+		}
+
+		inputs.Translators = append(inputs.Translators, translatorInput(translator))
+		for _, library := range libraries {
+			inputs.Libraries = append(inputs.Libraries, objectInput{Name: library.Name, Generation: library.Generation})
+		}
+	}
+
+	for _, translator := range unmatched {
+		inputs.Unmatched = append(inputs.Unmatched, translatorInput(translator))
+	}
+
+	for _, name := range tenant.Status.Namespaces {
+		namespace := &corev1.Namespace{}
+		if err := i.Client.Get(ctx, client.ObjectKey{Name: name}, namespace); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return "", err
+		}
+
+		inputs.Namespaces[name] = namespace.GetLabels()
+
+		if settings.Argo.Repositories.Enabled {
+			secrets := &corev1.SecretList{}
+			if err := i.repositoryReader().List(ctx, secrets, client.InNamespace(name), client.HasLabels{meta.RepositoryLabel}); err != nil {
+				return "", err
+			}
+
+			for _, secret := range secrets.Items {
+				inputs.Repositories = append(inputs.Repositories, objectInput{
+					Name:    secret.Namespace + "/" + secret.Name,
+					Version: secret.ResourceVersion,
+				})
+			}
+		}
+	}
+
+	// The reflected repositories are not stamped with the hash
+	if len(inputs.Repositories) > 0 {
+		return "", nil
+	}
+
+	if namespace, name := meta.TenantValuesFrom(tenant); name != "" {
+		values := &corev1.ConfigMap{}
+		err := i.uncachedReader().Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, values)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return "", err
+		}
+
+		inputs.Values = values.ResourceVersion
+	}
+
+	if i.provisionProxyService(tenant) {
+		service := &corev1.Service{}
+		err := i.Client.Get(ctx, client.ObjectKey{
+			Namespace: settings.Proxy.CapsuleProxyServiceNamespace,
+			Name:      settings.Proxy.CapsuleProxyServiceName,
+		}, service)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return "", err
+		}
+
+		inputs.ProxyService = service.ResourceVersion
+	}
+
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Returns true if the last reconciliation of the tenant succeeded and all managed objects were reconciled
+// with the same inputs
+func (i *TenancyController) inputsUnchanged(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+	hash string,
+) bool {
+	if hash == "" {
+		return false
+	}
+
+	for _, translator := range translators {
+		condition := translator.GetTenantCondition(tenant)
+		if condition == nil || condition.Type != meta.ReadyCondition || condition.Status != metav1.ConditionTrue {
+			return false
+		}
+	}
+
+	for _, obj := range i.inputHashObjects(tenant) {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			return false
+		}
+
+		if meta.InputHash(obj) != hash {
+			return false
+		}
+	}
+
+	// The policy is kept in a shared object, which is not stamped
+	return i.argoPolicyPresent(ctx, tenant)
+}
+
+// Managed objects of the tenant which carry the input hash
+func (i *TenancyController) inputHashObjects(tenant *capsulev1beta2.Tenant) []client.Object {
+	settings := i.settings(tenant)
+
+	objects := []client.Object{
+		&argocdv1alpha1.AppProject{ObjectMeta: metav1.ObjectMeta{
+			Name:      meta.TenantProjectName(tenant),
+			Namespace: settings.Argo.Namespace,
+		}},
+	}
+
+	if i.provisionProxyService(tenant) {
+		namespace, name := settings.TenantServiceAccount(tenant)
+
+		objects = append(objects,
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: tenant.Name, Namespace: settings.Proxy.CapsuleProxyServiceNamespace}},
+			&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tenant.Name, Namespace: settings.Argo.Namespace}},
+		)
+	}
+
+	return objects
+}
+
+// Context carrying the input hash of the current reconciliation
+func withInputHash(ctx context.Context, hash string) context.Context {
+	return context.WithValue(ctx, inputHashKey{}, &inputHashState{hash: hash})
+}
+
+// Holds back the input hash of the current reconciliation, when an asset is still pending (eg. a token which is
+// not yet issued). The objects are then stamped without hash, so the next reconciliation is not skipped
+func holdInputHash(ctx context.Context) {
+	if state, ok := ctx.Value(inputHashKey{}).(*inputHashState); ok {
+		state.held.Store(true)
+	}
+}
+
+// Stores the input hash of the current reconciliation on the managed object
+func stampInputHash(ctx context.Context, obj client.Object) {
+	state, ok := ctx.Value(inputHashKey{}).(*inputHashState)
+	if !ok || state.hash == "" {
+		return
+	}
+
+	if state.held.Load() {
+		annotations := obj.GetAnnotations()
+		delete(annotations, meta.AnnotationInputHash)
+		obj.SetAnnotations(annotations)
+
+		return
+	}
+
+	meta.SetInputHash(obj, state.hash)
+}
+
+// Returns true if the translator provisions assets which are not stamped with the hash (Applications, accounts
+// and project variants with their policies) or tokens which expire
+func translatorUnstamped(translator *v1alpha1.ArgoTranslator) bool {
+	if len(translator.Spec.Applications) > 0 || len(translator.Spec.Accounts) > 0 || len(translator.Spec.Projects) > 0 {
+		return true
+	}
+
+	for _, role := range translator.Spec.ProjectRoles {
+		if role.Token != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func translatorInput(translator *v1alpha1.ArgoTranslator) objectInput {
+	return objectInput{Name: translator.Name, Generation: translator.Generation, Labels: translator.GetLabels()}
+}
+
+// Changes at the end of each resync interval, so the assets are reconciled even when the inputs did not change.
+// The interval is shifted per tenant, so the tenants are not all reconciled at once
+func resyncBucket(tenant *capsulev1beta2.Tenant, resync time.Duration) int64 {
+	interval := int64(resync / time.Second)
+	if interval <= 0 {
+		return 0
+	}
+
+	offset := fnv.New32a()
+	_, _ = offset.Write([]byte(tenant.UID))
+
+	return (time.Now().Unix() + int64(offset.Sum32())%interval) / interval
+}
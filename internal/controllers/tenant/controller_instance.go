@@ -0,0 +1,203 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Selects the argocd instance for the tenant. When the instance changed, the assets are removed from
+// the previous instance before the tenant is reconciled into the new instance.
+func (i *TenancyController) reconcileArgoInstance(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) error {
+	desired, err := i.tenantInstance(tenant, translators)
+	if err != nil {
+		return err
+	}
+
+	current := meta.TenantManagedInstance(tenant)
+	if desired == current {
+		return nil
+	}
+
+	// Remove the assets from the previous instance (if it's still configured)
+	if _, err := i.Settings.Get().GetInstance(current); err == nil && controllerutil.ContainsFinalizer(tenant, meta.Finalizer()) {
+		log.Info("migrating tenant to argocd instance", "from", current, "to", desired)
+
+		if err := i.removeArgoInstance(ctx, log, tenant); err != nil {
+			return fmt.Errorf("failed to remove tenant from argocd instance %s: %w", current, err)
+		}
+	}
+
+	// Record the new instance on the tenant
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(tenant), tenant); err != nil {
+			return err
+		}
+
+		annotations := tenant.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		if desired == "" {
+			delete(annotations, meta.AnnotationManagedInstance)
+		} else {
+			annotations[meta.AnnotationManagedInstance] = desired
+		}
+
+		tenant.SetAnnotations(annotations)
+
+		return i.Client.Update(ctx, tenant)
+	})
+	if err != nil {
+		return err
+	}
+
+	// Source namespaces of both instances change
+	i.requestSourceNamespaces()
+
+	return nil
+}
+
+// Selected instance for the tenant, the annotation on the tenant takes precedence over the translators
+func (i *TenancyController) tenantInstance(
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) (string, error) {
+	instance := meta.TenantInstance(tenant)
+	if instance == "" {
+		for _, translator := range translators {
+			if translator.Spec.Instance == "" {
+				continue
+			}
+
+			if instance != "" && instance != translator.Spec.Instance {
+				return "", fmt.Errorf("translators select different argocd instances (%s, %s)", instance, translator.Spec.Instance)
+			}
+
+			instance = translator.Spec.Instance
+		}
+	}
+
+	if _, err := i.Settings.Get().GetInstance(instance); err != nil {
+		return "", err
+	}
+
+	return instance, nil
+}
+
+// Removes all assets of the tenant from the instance currently managing the tenant
+func (i *TenancyController) removeArgoInstance(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+) error {
+	cfg := i.settings(tenant)
+
+	if err := i.lifecycleArgoApplications(ctx, log, tenant, nil, false); err != nil {
+		return err
+	}
+
+	if err := i.lifecycleArgoRepositories(ctx, log, tenant, nil, false); err != nil {
+		return err
+	}
+
+	tokens, err := i.collectProjectRoleTokens(ctx, tenant, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := i.reflectProjectRoleTokens(ctx, log, tenant, tokens); err != nil {
+		return err
+	}
+
+	if err := i.reconcileArgoAccounts(ctx, log, tenant, nil); err != nil {
+		return err
+	}
+
+	// Other tenants may still share the appproject in the instance
+	members, err := i.projectMembers(ctx, tenant, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := i.reflectArgoRBAC(ctx, log, tenant, members); err != nil {
+		return err
+	}
+
+	if err := i.lifecycleRemoteClusterSecrets(ctx, log, tenant, nil, false); err != nil {
+		return err
+	}
+
+	if err := i.lifecycleArgoProjectVariants(ctx, log, tenant, nil, false); err != nil {
+		return err
+	}
+
+	// Remove the cluster secret
+	cluster := &corev1.Secret{}
+	err = i.Client.Get(ctx, client.ObjectKey{Name: tenant.Name, Namespace: cfg.Argo.Namespace}, cluster)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	if err == nil && meta.HasTenantOwnerReference(cluster, tenant) {
+		log.V(5).Info("removing cluster secret", "secret", cluster.Name, "namespace", cluster.Namespace)
+		if err := i.Client.Delete(ctx, cluster); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	// Remove the appproject
+	appProject := &argocdv1alpha1.AppProject{}
+	err = i.Client.Get(ctx, client.ObjectKey{Name: meta.TenantProjectName(tenant), Namespace: cfg.Argo.Namespace}, appProject)
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if !meta.HasTenantOwnerReference(appProject, tenant) {
+		return nil
+	}
+
+	// Other tenants still contribute to the shared appproject
+	if len(members) > 0 {
+		return i.leaveArgoProject(ctx, log, tenant, appProject, members, nil)
+	}
+
+	log.V(5).Info("removing appproject", "appproject", appProject.Name, "namespace", appProject.Namespace)
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(appProject), appProject); err != nil {
+			return err
+		}
+
+		meta.RemoveTranslatingFinalizers(appProject)
+		for _, member := range meta.GetProjectMemberFinalizers(appProject) {
+			controllerutil.RemoveFinalizer(appProject, meta.ProjectMemberFinalizer(member))
+		}
+
+		return i.Client.Update(ctx, appProject)
+	})
+	if err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	if err := i.Client.Delete(ctx, appProject); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
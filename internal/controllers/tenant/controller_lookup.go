@@ -0,0 +1,171 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// Object read with lookup, the name is empty for lists
+type lookupKey struct {
+	gvk       schema.GroupVersionKind
+	namespace string
+	name      string
+}
+
+// Tracks the objects the templates of the tenants read with lookup
+type lookupTracker struct {
+	sync.Mutex
+	tenants map[lookupKey]map[string]struct{}
+	watched map[schema.GroupVersionKind]struct{}
+}
+
+func newLookupTracker() *lookupTracker {
+	return &lookupTracker{
+		tenants: make(map[lookupKey]map[string]struct{}),
+		watched: make(map[schema.GroupVersionKind]struct{}),
+	}
+}
+
+// Records the lookup for the tenant, returns true if the kind is not watched yet
+func (t *lookupTracker) track(tenant string, key lookupKey) bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if _, ok := t.tenants[key]; !ok {
+		t.tenants[key] = make(map[string]struct{})
+	}
+
+	t.tenants[key][tenant] = struct{}{}
+
+	if _, ok := t.watched[key.gvk]; ok {
+		return false
+	}
+
+	t.watched[key.gvk] = struct{}{}
+
+	return true
+}
+
+// Removes the kind from the watched kinds (eg. when the watch could not be started)
+func (t *lookupTracker) unwatch(gvk schema.GroupVersionKind) {
+	t.Lock()
+	defer t.Unlock()
+
+	delete(t.watched, gvk)
+}
+
+// Removes all lookups of the tenant
+func (t *lookupTracker) reset(tenant string) {
+	t.Lock()
+	defer t.Unlock()
+
+	for key, tenants := range t.tenants {
+		delete(tenants, tenant)
+
+		if len(tenants) == 0 {
+			delete(t.tenants, key)
+		}
+	}
+}
+
+// Tenants which read the object (directly or by listing its namespace)
+func (t *lookupTracker) dependents(gvk schema.GroupVersionKind, namespace string, name string) []string {
+	t.Lock()
+	defer t.Unlock()
+
+	set := make(map[string]struct{})
+	for _, key := range []lookupKey{
+		{gvk: gvk, namespace: namespace, name: name},
+		{gvk: gvk, namespace: namespace},
+	} {
+		for tenant := range t.tenants[key] {
+			set[tenant] = struct{}{}
+		}
+	}
+
+	tenants := make([]string, 0, len(set))
+	for tenant := range set {
+		tenants = append(tenants, tenant)
+	}
+
+	return tenants
+}
+
+// Lookup for the templates of the tenant, the read objects are tracked to reconcile the tenant on changes
+func (i *TenancyController) tenantLookup(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+) *tpl.Lookup {
+	var reader client.Reader = i.Client
+	if i.cache != nil {
+		reader = i.cache
+	}
+
+	return tpl.NewLookup(ctx, reader, i.Client.RESTMapper(), i.settings(tenant), func(gvk schema.GroupVersionKind, namespace string, name string) {
+		if i.lookups == nil || !i.lookups.track(tenant.Name, lookupKey{gvk: gvk, namespace: namespace, name: name}) {
+			return
+		}
+
+		if err := i.watchLookup(gvk); err != nil {
+			log.Error(err, "failed to watch lookup resource", "gvk", gvk.String())
+			i.lookups.unwatch(gvk)
+		}
+	})
+}
+
+// Namespaces of the lookup cache. Without lookup namespaces only cluster-scoped resources can be read, which are
+// cached without namespace restriction
+func lookupNamespaces(settings *v1alpha1.ArgoAddonSpec) map[string]cache.Config {
+	if len(settings.Lookup.Namespaces) == 0 {
+		return nil
+	}
+
+	namespaces := make(map[string]cache.Config, len(settings.Lookup.Namespaces))
+	for _, namespace := range settings.Lookup.Namespaces {
+		namespaces[namespace] = cache.Config{}
+	}
+
+	return namespaces
+}
+
+// Watches the kind to reconcile the tenants which read the changed objects
+func (i *TenancyController) watchLookup(gvk schema.GroupVersionKind) error {
+	if i.controller == nil || i.cache == nil {
+		return nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+
+	return i.controller.Watch(source.Kind(i.cache, obj), i.LookupHandler(gvk))
+}
+
+// Handler to reconcile the Tenants which read the object with lookup
+func (i *TenancyController) LookupHandler(gvk schema.GroupVersionKind) handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		var requests []reconcile.Request
+		for _, tenant := range i.lookups.dependents(gvk, a.GetNamespace(), a.GetName()) {
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: tenant,
+				},
+			})
+		}
+
+		return requests
+	})
+}
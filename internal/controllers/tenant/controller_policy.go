@@ -0,0 +1,279 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ArgoCD resource of the argocd-operator
+var argoCDGroupVersionKind = schema.GroupVersionKind{
+	Group:   "argoproj.io",
+	Version: "v1beta1",
+	Kind:    "ArgoCD",
+}
+
+// Applies the policy of the tenant to the configured rbac target. An empty policy removes the tenant
+func (i *TenancyController) reflectArgoPolicy(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	csv string,
+) error {
+	return i.reflectArgoProjectPolicy(ctx, log, tenant, argo.ArgoPolicyName(tenant), csv)
+}
+
+// Returns true if the policy of the tenant is present on the configured rbac target
+func (i *TenancyController) argoPolicyPresent(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+) bool {
+	target := i.policyTarget(tenant)
+
+	switch target.Kind {
+	case v1alpha1.RBACTargetArgoCD:
+		instance := &unstructured.Unstructured{}
+		instance.SetGroupVersionKind(argoCDGroupVersionKind)
+		if err := i.Client.Get(ctx, client.ObjectKey{Name: target.Name, Namespace: target.Namespace}, instance); err != nil {
+			return false
+		}
+
+		policy, _, _ := unstructured.NestedString(instance.Object, "spec", "rbac", "policy")
+
+		return argo.GetPolicySection(policy, argo.ArgoPolicyName(tenant)) != ""
+	default:
+		configmap := &corev1.ConfigMap{}
+		if err := i.Client.Get(ctx, client.ObjectKey{Name: target.Name, Namespace: target.Namespace}, configmap); err != nil {
+			return false
+		}
+
+		_, ok := configmap.Data[argo.ArgoPolicyName(tenant)]
+
+		return ok
+	}
+}
+
+// Applies a policy (by key) of the tenant to the configured rbac target. An empty policy removes the key
+func (i *TenancyController) reflectArgoProjectPolicy(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	key string,
+	csv string,
+) error {
+	return i.reflectTargetPolicy(ctx, log, tenant, i.policyTarget(tenant), key, csv)
+}
+
+// Applies a policy (by key) of the tenant to the given rbac target. An empty policy removes the key
+func (i *TenancyController) reflectTargetPolicy(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	target argo.PolicyTarget,
+	key string,
+	csv string,
+) error {
+	switch target.Kind {
+	case v1alpha1.RBACTargetArgoCD:
+		return i.reflectArgoCDPolicy(ctx, log, tenant, target, key, csv)
+	default:
+		return i.reflectConfigMapPolicy(ctx, log, tenant, target, key, csv)
+	}
+}
+
+// Configured rbac target of the tenant
+func (i *TenancyController) policyTarget(tenant *capsulev1beta2.Tenant) argo.PolicyTarget {
+	cfg := i.settings(tenant)
+
+	if cfg.Argo.RBACTarget.Kind == v1alpha1.RBACTargetArgoCD {
+		return argo.PolicyTarget{Kind: v1alpha1.RBACTargetArgoCD, Namespace: cfg.Argo.Namespace, Name: cfg.Argo.RBACTarget.Name}
+	}
+
+	return argo.PolicyTarget{Kind: v1alpha1.RBACTargetConfigMap, Namespace: cfg.Argo.Namespace, Name: cfg.Argo.RBACConfigMap}
+}
+
+// Removes the policies of the tenant from the previous rbac target, when the rbac target changed (kind, namespace
+// or name). The applied target is recorded on the tenant
+func (i *TenancyController) reconcileArgoPolicyTarget(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+) error {
+	current := i.policyTarget(tenant)
+
+	previous, ok := argo.ParsePolicyTarget(meta.TenantManagedPolicyTarget(tenant))
+	if ok && previous != current {
+		log.V(3).Info("rbac target changed, removing policies from previous target", "from", previous.String(), "to", current.String())
+
+		keys, err := i.tenantPolicyKeys(ctx, tenant, previous.Namespace)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			err := i.reflectTargetPolicy(ctx, log, tenant, previous, key, "")
+			if err != nil && !k8serrors.IsNotFound(err) && !apimeta.IsNoMatchError(err) {
+				return fmt.Errorf("failed to remove policy %s from %s: %w", key, previous.String(), err)
+			}
+		}
+	}
+
+	return i.recordPolicyTarget(ctx, tenant, current.String())
+}
+
+// Policy keys which may be applied for the tenant: the appproject, the previous appproject and the project variants
+func (i *TenancyController) tenantPolicyKeys(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	namespace string,
+) ([]string, error) {
+	keys := []string{argo.ArgoPolicyName(tenant)}
+	if previous := meta.TenantManagedProject(tenant); previous != "" && previous != meta.TenantProjectName(tenant) {
+		keys = append(keys, argo.ProjectPolicyName(previous))
+	}
+
+	variants := &argocdv1alpha1.AppProjectList{}
+	if err := i.Client.List(ctx, variants,
+		client.InNamespace(namespace),
+		client.MatchingLabels{meta.ManagedTenantLabel: tenant.Name},
+		client.HasLabels{meta.ManagedProjectVariantLabel},
+	); err != nil {
+		return nil, err
+	}
+
+	for _, variant := range variants.Items {
+		if meta.HasTenantOwnerReference(&variant, tenant) {
+			keys = append(keys, argo.ProjectPolicyName(variant.Name))
+		}
+	}
+
+	return keys, nil
+}
+
+// Records the applied rbac target on the tenant
+func (i *TenancyController) recordPolicyTarget(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	target string,
+) error {
+	if meta.TenantManagedPolicyTarget(tenant) == target {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(tenant), tenant); err != nil {
+			return err
+		}
+
+		annotations := tenant.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		annotations[meta.AnnotationManagedPolicyTarget] = target
+		tenant.SetAnnotations(annotations)
+
+		return i.Client.Update(ctx, tenant)
+	})
+}
+
+// Applies the policy as policy.<project>.csv to the rbac configmap
+func (i *TenancyController) reflectConfigMapPolicy(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	target argo.PolicyTarget,
+	key string,
+	csv string,
+) error {
+	// Initialize target configmap
+	configmap := &corev1.ConfigMap{}
+	err := i.Client.Get(ctx, client.ObjectKey{
+		Name:      target.Name,
+		Namespace: target.Namespace}, configmap)
+	if err != nil {
+		return err
+	}
+
+	current, exists := configmap.Data[key]
+	if (csv == "" && !exists) || (csv != "" && reflect.DeepEqual(current, csv)) {
+		log.V(7).Info("csv already updated", "tenant", tenant.Name, "key", key)
+
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() (conflictErr error) {
+		_, conflictErr = controllerutil.CreateOrUpdate(ctx, i.Client, configmap, func() error {
+			if csv == "" {
+				delete(configmap.Data, key)
+
+				return nil
+			}
+
+			if configmap.Data == nil {
+				configmap.Data = make(map[string]string)
+			}
+
+			configmap.Data[key] = csv
+
+			return nil
+		})
+
+		return
+	})
+}
+
+// Applies the policy to a managed section of spec.rbac.policy of the ArgoCD resource (argocd-operator)
+func (i *TenancyController) reflectArgoCDPolicy(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	target argo.PolicyTarget,
+	section string,
+	csv string,
+) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		instance := &unstructured.Unstructured{}
+		instance.SetGroupVersionKind(argoCDGroupVersionKind)
+		if err := i.Client.Get(ctx, client.ObjectKey{
+			Name:      target.Name,
+			Namespace: target.Namespace}, instance); err != nil {
+			return err
+		}
+
+		policy, _, err := unstructured.NestedString(instance.Object, "spec", "rbac", "policy")
+		if err != nil {
+			return err
+		}
+
+		updated := argo.ReplacePolicySection(policy, section, csv)
+		if updated == policy {
+			log.V(7).Info("csv already updated", "tenant", tenant.Name, "argocd", instance.GetName())
+
+			return nil
+		}
+
+		if err := unstructured.SetNestedField(instance.Object, updated, "spec", "rbac", "policy"); err != nil {
+			return err
+		}
+
+		log.V(5).Info("updating argocd policy", "tenant", tenant.Name, "argocd", instance.GetName())
+
+		return i.Client.Update(ctx, instance)
+	})
+}
@@ -0,0 +1,130 @@
+package tenant
+
+import (
+	"context"
+	"sort"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Tenant contributing to an appproject with the translators selecting it
+type projectMember struct {
+	tenant      *capsulev1beta2.Tenant
+	translators []*v1alpha1.ArgoTranslator
+}
+
+// Collects the tenants contributing to the appproject of the tenant, sorted by name. Tenants of the same
+// project group share one appproject, otherwise the tenant is the only member. Tenants are only members
+// while they are not being deleted and have translators.
+func (i *TenancyController) projectMembers(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) ([]projectMember, error) {
+	var members []projectMember
+	if tenant.ObjectMeta.DeletionTimestamp.IsZero() && len(translators) > 0 {
+		members = append(members, projectMember{tenant: tenant, translators: translators})
+	}
+
+	if meta.TenantProjectGroup(tenant) == "" {
+		return members, nil
+	}
+
+	tenants := &capsulev1beta2.TenantList{}
+	if err := i.Client.List(ctx, tenants); err != nil {
+		return nil, err
+	}
+
+	allTranslators := &v1alpha1.ArgoTranslatorList{}
+	if err := i.Client.List(ctx, allTranslators); err != nil {
+		return nil, err
+	}
+
+	project := meta.TenantProjectName(tenant)
+	namespace := i.settings(tenant).Argo.Namespace
+
+	for idx := range tenants.Items {
+		other := &tenants.Items[idx]
+		if other.Name == tenant.Name || !other.ObjectMeta.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		if meta.TenantProjectName(other) != project || !i.Settings.Get().SelectsTenant(other) {
+			continue
+		}
+
+		// Members must be reconciled into the same instance
+		if i.settings(other).Argo.Namespace != namespace {
+			continue
+		}
+
+		matched, _ := i.aggregateConfigTranslators(allTranslators, other)
+		if len(matched) == 0 {
+			continue
+		}
+
+		members = append(members, projectMember{tenant: other, translators: matched})
+	}
+
+	sort.Slice(members, func(a, b int) bool {
+		return members[a].tenant.Name < members[b].tenant.Name
+	})
+
+	return members, nil
+}
+
+// Verify if the appproject is owned by any of the members
+func projectOwnedByMembers(appProject *argocdv1alpha1.AppProject, members []projectMember) bool {
+	for _, member := range members {
+		if meta.HasTenantOwnerReference(appProject, member.tenant) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Handler to reconcile the other Tenants of the same project group, as they share the appproject
+func (i *TenancyController) ProjectGroupHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		tenant, ok := a.(*capsulev1beta2.Tenant)
+		if !ok || (meta.TenantProjectGroup(tenant) == "" && meta.TenantManagedProject(tenant) == meta.TenantProjectName(tenant)) {
+			return nil
+		}
+
+		tenants := &capsulev1beta2.TenantList{}
+		if err := i.Client.List(ctx, tenants); err != nil {
+			i.Log.Error(err, "Failed to list tenants for reconciliation")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for idx := range tenants.Items {
+			other := &tenants.Items[idx]
+			if other.Name == tenant.Name || !i.managesTenant(other) {
+				continue
+			}
+
+			// Members of the current and the previous appproject (rename)
+			project := meta.TenantProjectName(other)
+			if project != meta.TenantProjectName(tenant) && project != meta.TenantManagedProject(tenant) {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: other.Name,
+				},
+			})
+		}
+
+		return requests
+	})
+}
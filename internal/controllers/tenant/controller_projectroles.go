@@ -0,0 +1,302 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Tokens of the project roles, collected before the appproject is reconciled
+type projectRoleTokens struct {
+	// Signature to mint new tokens
+	signature []byte
+
+	// Roles with a token issued by the tenant
+	desired map[string]*v1alpha1.ArgocdProjectRolesTranslator
+
+	// Token ids of the current secrets by role
+	current map[string]string
+
+	// Token ids which are no longer used
+	revoked []string
+
+	// Secrets which are no longer used
+	obsolete []*corev1.Secret
+
+	// Tokens which were issued and stored, registered when reflecting the roles
+	issued map[string]argocdv1alpha1.JWTToken
+}
+
+// Name of a translator role as used in the argo policies
+func translatorRoleName(tenant *capsulev1beta2.Tenant, translator *v1alpha1.ArgoTranslator, role string) string {
+	if r := translator.GetProjectRole(role); r != nil && r.IsProjectScoped() {
+		return argo.ProjectRoleName(meta.TenantProjectName(tenant), role)
+	}
+
+	return argo.TenantPolicy(tenant, role)
+}
+
+// Collects the current token secrets of the project roles
+func (i *TenancyController) collectProjectRoleTokens(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) (*projectRoleTokens, error) {
+	tokens := &projectRoleTokens{
+		desired: make(map[string]*v1alpha1.ArgocdProjectRolesTranslator),
+		current: make(map[string]string),
+		issued:  make(map[string]argocdv1alpha1.JWTToken),
+	}
+
+	desired := tokens.desired
+	for _, translator := range translators {
+		for idx := range translator.Spec.ProjectRoles {
+			role := &translator.Spec.ProjectRoles[idx]
+			if !role.IsProjectScoped() || role.Token == nil {
+				continue
+			}
+
+			if !utils.ContainsString(tenant.Status.Namespaces, role.Token.Namespace) {
+				// Tokens of shared appprojects are issued by the tenant owning the namespace
+				if meta.TenantProjectGroup(tenant) != "" {
+					continue
+				}
+
+				return nil, fmt.Errorf("namespace %s for the token of role %s is not part of tenant %s", role.Token.Namespace, role.Name, tenant.Name)
+			}
+
+			desired[role.Name] = role
+		}
+	}
+
+	secrets := &corev1.SecretList{}
+	if err := i.Client.List(ctx, secrets,
+		client.MatchingLabels{meta.ManagedTenantLabel: tenant.Name},
+		client.HasLabels{meta.ManagedProjectRoleLabel},
+	); err != nil {
+		return nil, err
+	}
+
+	for idx := range secrets.Items {
+		secret := &secrets.Items[idx]
+		role := secret.Labels[meta.ManagedProjectRoleLabel]
+		id, _ := argo.AccountTokenID(string(secret.Data["token"]))
+
+		if target, ok := desired[role]; ok && tokenSecret(projectRoleTokenName(tenant, role), target.Token) == client.ObjectKeyFromObject(secret) {
+			tokens.current[role] = id
+
+			continue
+		}
+
+		tokens.revoked = append(tokens.revoked, id)
+		tokens.obsolete = append(tokens.obsolete, secret)
+	}
+
+	if len(desired) == 0 {
+		return tokens, nil
+	}
+
+	cfg := i.settings(tenant)
+	argoSecret := &corev1.Secret{}
+	if err := i.Client.Get(ctx, client.ObjectKey{Name: cfg.Argo.Secret, Namespace: cfg.Argo.Namespace}, argoSecret); err != nil {
+		return nil, err
+	}
+
+	tokens.signature = argoSecret.Data[argo.ServerSignatureKey]
+
+	return tokens, nil
+}
+
+// Mints the tokens of the roles which are missing or due for rotation. The tokens are stored in the secrets first
+// and registered on the roles when the appproject is reflected, a token which could not be stored is never registered.
+func (i *TenancyController) issueProjectRoleTokens(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	appProject *argocdv1alpha1.AppProject,
+	tokens *projectRoleTokens,
+) error {
+	now := time.Now()
+
+	registered := make(map[string][]argocdv1alpha1.JWTToken, len(appProject.Spec.Roles))
+	for _, role := range appProject.Spec.Roles {
+		registered[role.Name] = role.JWTTokens
+	}
+
+	names := make([]string, 0, len(tokens.desired))
+	for name := range tokens.desired {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if id, ok := tokens.current[name]; ok {
+			if rotation, ok := argo.ProjectRoleTokenRotation(registered[name], id); ok && (rotation.IsZero() || now.Before(rotation)) {
+				requeueAt(ctx, rotation)
+
+				continue
+			}
+		}
+
+		target := tokens.desired[name]
+
+		log.V(5).Info("issuing token for project role", "appproject", appProject.Name, "role", name)
+
+		signed, token, err := argo.MintProjectRoleToken(appProject.Name, name, tokens.signature, target.Token.GetExpiresIn(), now)
+		if err != nil {
+			return fmt.Errorf("failed to mint token for role %s: %w", name, err)
+		}
+
+		minted := mintedToken{
+			label:  meta.ManagedProjectRoleLabel,
+			value:  name,
+			secret: tokenSecret(projectRoleTokenName(tenant, name), target.Token),
+			data: map[string][]byte{
+				"project": []byte(appProject.Name),
+				"role":    []byte(name),
+				"token":   []byte(signed),
+			},
+		}
+
+		log.V(5).Info("storing project role token", "role", name, "secret", minted.secret.Name, "namespace", minted.secret.Namespace)
+		if err := i.storeArgoToken(ctx, log, tenant, minted); err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		tokens.current[name] = token.ID
+		tokens.issued[name] = token
+		requeueAt(ctx, argo.TokenRotation(token.IssuedAt, token.ExpiresAt))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Reflects the project roles from the translators of all members on the appproject. Only the roles which were added
+// by the controller are removed again. Issued tokens are added to their roles, the previous tokens are kept until
+// they expire.
+func (i *TenancyController) reflectProjectRoles(
+	log logr.Logger,
+	appProject *argocdv1alpha1.AppProject,
+	existing []argocdv1alpha1.ProjectRole,
+	members []projectMember,
+	tokens *projectRoleTokens,
+) error {
+	now := time.Now()
+
+	current := make(map[string]argocdv1alpha1.ProjectRole, len(existing))
+	for _, role := range existing {
+		current[role.Name] = role
+	}
+
+	var desired []*argocdv1alpha1.ProjectRole
+	var names []string
+	for _, member := range members {
+		roles := utils.GetClusterRolePermissions(member.tenant)
+
+		for _, translator := range member.translators {
+			for _, argopolicy := range translator.Spec.ProjectRoles {
+				if !argopolicy.IsProjectScoped() {
+					continue
+				}
+
+				// Roles of the same name are shared by the members
+				var role *argocdv1alpha1.ProjectRole
+				for _, present := range desired {
+					if present.Name == argopolicy.Name {
+						role = present
+					}
+				}
+
+				if role == nil {
+					role = &argocdv1alpha1.ProjectRole{
+						Name:        argopolicy.Name,
+						Description: "Managed by translator " + translator.Name,
+						Policies:    argo.ProjectRolePolicies(appProject.Name, argopolicy.Name, argopolicy.Policies),
+						JWTTokens:   argo.PruneProjectRoleTokens(current[argopolicy.Name].JWTTokens, now, tokens.revoked...),
+					}
+
+					if token, ok := tokens.issued[role.Name]; ok {
+						role.JWTTokens = append(role.JWTTokens, token)
+					}
+
+					desired = append(desired, role)
+					names = append(names, role.Name)
+				}
+
+				// Assign the selected groups
+				for _, clusterRole := range argopolicy.ClusterRoles {
+					for _, subject := range roles[clusterRole] {
+						if subject.Kind == rbacv1.GroupKind && !utils.ContainsString(role.Groups, subject.Name) {
+							role.Groups = append(role.Groups, subject.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// Keep the roles which are not managed by the controller
+	previous := meta.GetAnnotationList(appProject, meta.AnnotationManagedProjectRoles)
+	result := []argocdv1alpha1.ProjectRole{}
+	for _, role := range appProject.Spec.Roles {
+		if utils.ContainsString(previous, role.Name) || utils.ContainsString(names, role.Name) {
+			continue
+		}
+
+		result = append(result, role)
+	}
+
+	for _, role := range desired {
+		result = append(result, *role)
+	}
+
+	appProject.Spec.Roles = result
+	if len(appProject.Spec.Roles) == 0 {
+		appProject.Spec.Roles = nil
+	}
+
+	meta.SetAnnotationList(appProject, meta.AnnotationManagedProjectRoles, names)
+
+	log.V(7).Info("reflected project roles", "appproject", appProject.Name, "roles", names)
+
+	return nil
+}
+
+// Removes the obsolete token secrets
+func (i *TenancyController) reflectProjectRoleTokens(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	tokens *projectRoleTokens,
+) error {
+	for _, secret := range tokens.obsolete {
+		log.V(5).Info("removing project role token", "secret", secret.Name, "namespace", secret.Namespace)
+		if err := i.Client.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Default name of the secret for the token of a project role
+func projectRoleTokenName(tenant *capsulev1beta2.Tenant, role string) string {
+	return meta.TenantProjectName(tenant) + "-" + role
+}
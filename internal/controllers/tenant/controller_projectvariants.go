@@ -0,0 +1,354 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/reflection"
+	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Project variant with the translator declaring it
+type projectVariant struct {
+	translator *v1alpha1.ArgoTranslator
+	variant    *v1alpha1.ArgocdProjectVariant
+}
+
+// Collects the project variants of the translators by name. A variant may only be declared once
+func projectVariants(translators []*v1alpha1.ArgoTranslator) (map[string]projectVariant, error) {
+	variants := make(map[string]projectVariant)
+	for _, translator := range translators {
+		for idx := range translator.Spec.Projects {
+			variant := &translator.Spec.Projects[idx]
+			if existing, ok := variants[variant.Name]; ok {
+				return nil, fmt.Errorf(
+					"project variant %s is declared by translators %s and %s",
+					variant.Name, existing.translator.Name, translator.Name)
+			}
+
+			variants[variant.Name] = projectVariant{translator: translator, variant: variant}
+		}
+	}
+
+	return variants, nil
+}
+
+// Reconciles the appprojects of the project variants declared by the translators of the tenant. Appprojects
+// of variants which are no longer declared are removed.
+func (i *TenancyController) reconcileArgoProjectVariants(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) (err error) {
+	var variants map[string]projectVariant
+	var variantErr error
+	if tenant.ObjectMeta.DeletionTimestamp.IsZero() {
+		variants, err = projectVariants(translators)
+		if err != nil {
+			return err
+		}
+
+		// Variants would collide between the members of a shared appproject
+		if meta.TenantProjectGroup(tenant) != "" && len(variants) > 0 {
+			variants = nil
+			variantErr = errors.New("project variants are not supported for tenants in a project group")
+		}
+	}
+
+	names := make([]string, 0, len(variants))
+	for name := range variants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := i.reflectArgoProjectVariant(ctx, log, tenant, variants[name]); err != nil {
+			return err
+		}
+	}
+
+	return errors.Join(i.lifecycleArgoProjectVariants(ctx, log, tenant, variants, meta.TenantDecoupleProject(tenant)), variantErr)
+}
+
+// Creates or updates the appproject and the policy of a project variant
+func (i *TenancyController) reflectArgoProjectVariant(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	variant projectVariant,
+) error {
+	appProject := &argocdv1alpha1.AppProject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      argo.ProjectVariantName(tenant, variant.variant.Name),
+			Namespace: i.settings(tenant).Argo.Namespace,
+		},
+	}
+
+	gerr := i.Client.Get(ctx, client.ObjectKeyFromObject(appProject), appProject)
+	if gerr != nil && !k8serrors.IsNotFound(gerr) {
+		return gerr
+	}
+
+	// Don't Force, When project already exists
+	if !meta.HasTenantOwnerReference(appProject, tenant) && !k8serrors.IsNotFound(gerr) && !i.ForceTenant(tenant) {
+		log.V(1).Info("appproject already present, not overriding", "appproject", appProject.Name)
+
+		return ccaerrrors.NewObjectAlreadyExistsError(appProject)
+	}
+
+	destinations, err := i.projectVariantDestinations(ctx, tenant, variant.variant)
+	if err != nil {
+		return err
+	}
+
+	renderer, err := i.translatorRenderer(ctx, tenant, variant.translator)
+	if err != nil {
+		return err
+	}
+
+	log.V(5).Info("reconcile appproject variant", "appproject", appProject.Name, "variant", variant.variant.Name)
+
+	_, err = controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
+		cfg, err := renderer.VariantConfig(variant.variant)
+		if err != nil {
+			return err
+		}
+
+		log.V(7).Info(
+			"variant-config",
+			"translator", variant.translator.Name,
+			"appproject", appProject.Name,
+			"config", cfg.ProjectSpec)
+
+		// Prepare metadata
+		appProject.ObjectMeta.Labels = meta.TranslatorTrackingLabels(tenant)
+		appProject.ObjectMeta.Labels[meta.ManagedTranslatorLabel] = variant.translator.Name
+		appProject.ObjectMeta.Labels[meta.ManagedProjectVariantLabel] = variant.variant.Name
+		for key, value := range cfg.ProjectMeta.Labels {
+			appProject.ObjectMeta.Labels[key] = value
+		}
+
+		if appProject.ObjectMeta.Annotations == nil {
+			appProject.ObjectMeta.Annotations = make(map[string]string)
+		}
+		for key, value := range cfg.ProjectMeta.Annotations {
+			appProject.ObjectMeta.Annotations[key] = value
+		}
+
+		for _, finalizer := range cfg.ProjectMeta.Finalizers {
+			controllerutil.AddFinalizer(appProject, finalizer)
+		}
+
+		if meta.TenantReadOnly(tenant) {
+			appProject.Spec = cfg.ProjectSpec
+		} else {
+			if err := reflection.Merge(&appProject.Spec, &cfg.ProjectSpec); err != nil {
+				return fmt.Errorf("failed to merge project spec: %w", err)
+			}
+		}
+
+		// All destinations of the variant are named after the tenant
+		argo.ReplaceManagedDestinations(appProject, []string{tenant.Name}, destinations)
+
+		// Deny syncs while the tenant is cordoned
+		if err := reflectProjectSyncWindow(log, appProject, tenant.Name, i.cordonSyncWindow(tenant)); err != nil {
+			return err
+		}
+
+		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), appProject, tenant)
+	})
+	if err != nil {
+		return err
+	}
+
+	csv, err := i.reflectProjectVariantCSV(log, tenant, variant)
+	if err != nil {
+		return err
+	}
+
+	return i.reflectArgoProjectPolicy(ctx, log, tenant, argo.ProjectPolicyName(appProject.Name), csv)
+}
+
+// Destinations of a project variant. Without namespace selector the variant may deploy to all namespaces of the tenant.
+func (i *TenancyController) projectVariantDestinations(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	variant *v1alpha1.ArgocdProjectVariant,
+) (destinations []argocdv1alpha1.ApplicationDestination, err error) {
+	if !i.settings(tenant).Proxy.Enabled {
+		return nil, nil
+	}
+
+	server := i.settings(tenant).ProxyServiceString(tenant)
+	if variant.NamespaceSelector == nil {
+		return []argocdv1alpha1.ApplicationDestination{{
+			Name:      tenant.Name,
+			Server:    server,
+			Namespace: "*",
+		}}, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(variant.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector for project variant %s: %w", variant.Name, err)
+	}
+
+	namespaces := &corev1.NamespaceList{}
+	if err := i.Client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+
+	for _, namespace := range namespaces.Items {
+		if !utils.ContainsString(tenant.Status.Namespaces, namespace.Name) {
+			continue
+		}
+
+		destinations = append(destinations, argocdv1alpha1.ApplicationDestination{
+			Name:      tenant.Name,
+			Server:    server,
+			Namespace: namespace.Name,
+		})
+	}
+
+	sort.Slice(destinations, func(a, b int) bool {
+		return destinations[a].Namespace < destinations[b].Namespace
+	})
+
+	return destinations, nil
+}
+
+// Creates the CSV for the policy of a project variant
+func (i *TenancyController) reflectProjectVariantCSV(
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	variant projectVariant,
+) (string, error) {
+	var sb strings.Builder
+
+	project := argo.ProjectVariantName(tenant, variant.variant.Name)
+	readOnly := argo.VariantPolicyReadOnly(tenant, variant.variant.Name)
+	owner := argo.VariantPolicyOwner(tenant, variant.variant.Name)
+
+	// Get Permissions for Tenant
+	roles := utils.GetClusterRolePermissions(tenant)
+	demote := i.cordonReadOnly(tenant)
+
+	// Add Default Policies for the variant (cluster access is granted with the tenant's project)
+	for _, dlts := range argo.ProjectDefaultPolicies(project, readOnly, owner, false) {
+		sb.WriteString(dlts)
+	}
+
+	for _, role := range variant.variant.ProjectRoles {
+		roleName := argo.TenantPolicy(tenant, variant.variant.Name+":"+role.Name)
+
+		for _, pol := range role.Policies {
+			sb.WriteString(argo.PolicyString(roleName, project, pol))
+		}
+
+		sb.WriteString("\n")
+		for _, clusterRole := range role.ClusterRoles {
+			for _, subject := range roles[clusterRole] {
+				sb.WriteString(argo.BindingString(subject, roleName))
+				sb.WriteString(argo.BindingString(subject, readOnly))
+				if role.Owner && !demote {
+					sb.WriteString(argo.BindingString(subject, owner))
+				}
+			}
+		}
+	}
+
+	log.V(7).Info("templating variant csv", "appproject", project)
+
+	tmpl, err := template.New("rbac").Funcs(tpl.ExtraFuncMap()).Parse(sb.String())
+	if err != nil {
+		return "", err
+	}
+
+	rendered, err := sandbox.Execute(tmpl, nil)
+	if err != nil {
+		return "", err
+	}
+
+	finalCSV := string(rendered)
+	if err := argo.ValidateCSV(finalCSV); err != nil {
+		return "", errors.New("invalid argo csv: " + err.Error())
+	}
+
+	return finalCSV, nil
+}
+
+// Removes (or decouples) the appprojects of project variants which are no longer declared for the tenant
+func (i *TenancyController) lifecycleArgoProjectVariants(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	variants map[string]projectVariant,
+	decouple bool,
+) error {
+	projects := &argocdv1alpha1.AppProjectList{}
+	if err := i.Client.List(ctx, projects,
+		client.InNamespace(i.settings(tenant).Argo.Namespace),
+		client.MatchingLabels{meta.ManagedTenantLabel: tenant.Name},
+		client.HasLabels{meta.ManagedProjectVariantLabel},
+	); err != nil {
+		return err
+	}
+
+	for idx := range projects.Items {
+		appProject := &projects.Items[idx]
+		if !meta.HasTenantOwnerReference(appProject, tenant) {
+			continue
+		}
+
+		name := appProject.Labels[meta.ManagedProjectVariantLabel]
+		if _, ok := variants[name]; ok && appProject.Name == argo.ProjectVariantName(tenant, name) {
+			continue
+		}
+
+		if decouple {
+			log.V(5).Info("decoupling appproject variant", "appproject", appProject.Name)
+
+			_, err := controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
+				delete(appProject.Labels, meta.ManagedProjectVariantLabel)
+
+				return i.DecoupleTenant(appProject, tenant)
+			})
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		log.V(5).Info("removing appproject variant", "appproject", appProject.Name)
+
+		if err := i.reflectArgoProjectPolicy(ctx, log, tenant, argo.ProjectPolicyName(appProject.Name), ""); err != nil {
+			return err
+		}
+
+		if err := i.Client.Delete(ctx, appProject); err != nil && !k8serrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	return nil
+}
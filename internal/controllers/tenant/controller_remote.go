@@ -0,0 +1,503 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Delay until the tenant is reconciled again, when the token of the remote serviceaccount is not yet issued
+const remoteTokenRetry = 5 * time.Second
+
+// Client for a remote cluster, rebuilt when the kubeconfig changes
+type remoteClient struct {
+	version string
+	client  client.Client
+	config  *rest.Config
+}
+
+// Provisions the tenant on the remote clusters and returns the destinations for the appproject. When the tenant
+// is being deleted or has no translators, the tenant is removed (or decoupled) from the remote clusters.
+func (i *TenancyController) reconcileArgoRemoteClusters(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) (destinations []argocdv1alpha1.ApplicationDestination, err error) {
+	cfg := i.settings(tenant)
+
+	deleting := !tenant.ObjectMeta.DeletionTimestamp.IsZero()
+	provision := !deleting && len(translators) > 0 && i.provisionProxyService(tenant)
+	decouple := deleting && meta.TenantDecoupleProject(tenant)
+
+	desired := make(map[string]struct{})
+
+	var errs []error
+	for idx := range cfg.Clusters {
+		cluster := &cfg.Clusters[idx]
+		log := log.WithValues("cluster", cluster.Name)
+
+		if !provision {
+			if err := i.removeRemoteCluster(ctx, log, tenant, cluster, decouple); err != nil {
+				errs = append(errs, fmt.Errorf("failed to remove tenant from cluster %s: %w", cluster.Name, err))
+			}
+
+			continue
+		}
+
+		// Keep the cluster secret, even if the current state is invalid
+		desired[argo.RemoteClusterName(tenant, cluster.Name)] = struct{}{}
+
+		destination, err := i.reflectRemoteCluster(ctx, log, tenant, cluster)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to provision tenant on cluster %s: %w", cluster.Name, err))
+
+			continue
+		}
+
+		if destination != nil {
+			destinations = append(destinations, *destination)
+		}
+	}
+
+	if err := i.lifecycleRemoteClusterSecrets(ctx, log, tenant, desired, decouple); err != nil {
+		return destinations, err
+	}
+
+	return destinations, errors.Join(errs...)
+}
+
+// Provisions the tenant on a remote cluster (ServiceAccount, Token and Proxy-Service) and writes
+// the argo cluster secret. Returns no destination, while the token is not yet issued.
+func (i *TenancyController) reflectRemoteCluster(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	cluster *v1alpha1.ControllerRemoteCluster,
+) (*argocdv1alpha1.ApplicationDestination, error) {
+	remote, remoteConfig, err := i.remoteClient(ctx, cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteTenant := &capsulev1beta2.Tenant{}
+	if err := remote.Get(ctx, client.ObjectKey{Name: tenant.Name}, remoteTenant); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, fmt.Errorf("tenant %s does not exist", tenant.Name)
+		}
+
+		return nil, err
+	}
+
+	namespace := i.remoteServiceAccountNamespace(tenant, cluster)
+
+	log.V(7).Info("reconciling remote serviceaccount", "serviceaccount", tenant.Name, "namespace", namespace)
+
+	account := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenant.Name,
+			Namespace: namespace,
+		},
+	}
+
+	if err := i.reflectRemoteObject(ctx, log, remote, tenant, remoteTenant, account, func() error { return nil }); err != nil {
+		return nil, err
+	}
+
+	if err := i.addServiceAccountOwner(ctx, log, remote, remoteTenant, namespace, account.Name); err != nil {
+		return nil, err
+	}
+
+	token := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenant.Name,
+			Namespace: namespace,
+		},
+	}
+
+	err = i.reflectRemoteObject(ctx, log, remote, tenant, remoteTenant, token, func() error {
+		token.Type = corev1.SecretTypeServiceAccountToken
+
+		if token.Annotations == nil {
+			token.Annotations = make(map[string]string)
+		}
+		token.Annotations[corev1.ServiceAccountNameKey] = account.Name
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.reflectRemoteProxyService(ctx, log, remote, tenant, remoteTenant); err != nil {
+		return nil, err
+	}
+
+	if err := remote.Get(ctx, client.ObjectKeyFromObject(token), token); err != nil {
+		return nil, err
+	}
+
+	// No token was issued yet, the remote cluster is not watched so the tenant is requeued
+	if len(token.Data[corev1.ServiceAccountTokenKey]) == 0 {
+		log.V(5).Info("remote serviceaccount token not yet issued", "secret", token.Name, "namespace", token.Namespace)
+
+		holdInputHash(ctx)
+		requeueAt(ctx, time.Now().Add(remoteTokenRetry))
+
+		return nil, nil
+	}
+
+	destination := &argocdv1alpha1.ApplicationDestination{
+		Name:      argo.RemoteClusterName(tenant, cluster.Name),
+		Server:    cluster.Server,
+		Namespace: "*",
+	}
+
+	if err := i.reflectRemoteClusterSecret(ctx, log, tenant, cluster, remoteConfig, destination, string(token.Data[corev1.ServiceAccountTokenKey])); err != nil {
+		return nil, err
+	}
+
+	return destination, nil
+}
+
+// Replicates the capsule-proxy service for the tenant on the remote cluster
+func (i *TenancyController) reflectRemoteProxyService(
+	ctx context.Context,
+	log logr.Logger,
+	remote client.Client,
+	tenant *capsulev1beta2.Tenant,
+	remoteTenant *capsulev1beta2.Tenant,
+) error {
+	cfg := i.settings(tenant)
+
+	proxySvc := &corev1.Service{}
+	err := remote.Get(ctx, client.ObjectKey{
+		Namespace: cfg.Proxy.CapsuleProxyServiceNamespace,
+		Name:      cfg.Proxy.CapsuleProxyServiceName,
+	}, proxySvc)
+	if err != nil {
+		return fmt.Errorf("failed to resolve proxy service: %w", err)
+	}
+
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tenant.Name,
+			Namespace: cfg.Proxy.CapsuleProxyServiceNamespace,
+		},
+	}
+
+	return i.reflectRemoteObject(ctx, log, remote, tenant, remoteTenant, service, func() error {
+		service.Spec.Ports = proxySvc.Spec.Ports
+		service.Spec.Selector = proxySvc.Spec.Selector
+
+		return nil
+	})
+}
+
+// Creates or updates an object on the remote cluster, owned by the tenant on the remote cluster
+func (i *TenancyController) reflectRemoteObject(
+	ctx context.Context,
+	log logr.Logger,
+	remote client.Client,
+	tenant *capsulev1beta2.Tenant,
+	remoteTenant *capsulev1beta2.Tenant,
+	obj client.Object,
+	mutate func() error,
+) error {
+	err := remote.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	// Handle Force, if an object already exists with the same name
+	if !meta.HasTenantOwnerReference(obj, remoteTenant) {
+		if !i.ForceTenant(tenant) && !k8serrors.IsNotFound(err) {
+			log.V(5).Info("remote object already present, not overriding", "name", obj.GetName(), "namespace", obj.GetNamespace())
+
+			return ccaerrrors.NewObjectAlreadyExistsError(obj)
+		}
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, remote, obj, func() error {
+		obj.SetLabels(meta.WithTranslatorTrackingLabels(obj, tenant))
+
+		if err := mutate(); err != nil {
+			return err
+		}
+
+		return meta.AddDynamicTenantOwnerReference(ctx, remote.Scheme(), obj, remoteTenant)
+	})
+
+	return err
+}
+
+// Removes (or decouples) the assets of the tenant from a remote cluster
+func (i *TenancyController) removeRemoteCluster(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	cluster *v1alpha1.ControllerRemoteCluster,
+	decouple bool,
+) error {
+	remote, _, err := i.remoteClient(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	// Assets are garbage collected with the tenant on the remote cluster
+	remoteTenant := &capsulev1beta2.Tenant{}
+	if err := remote.Get(ctx, client.ObjectKey{Name: tenant.Name}, remoteTenant); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	namespace := i.remoteServiceAccountNamespace(tenant, cluster)
+
+	objects := []client.Object{
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: tenant.Name, Namespace: i.settings(tenant).Proxy.CapsuleProxyServiceNamespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: tenant.Name, Namespace: namespace}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: tenant.Name, Namespace: namespace}},
+	}
+
+	for _, obj := range objects {
+		if err := remote.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return err
+		}
+
+		if !meta.HasTenantOwnerReference(obj, remoteTenant) {
+			continue
+		}
+
+		if !decouple {
+			log.V(5).Info("removing remote object", "name", obj.GetName(), "namespace", obj.GetNamespace())
+			if err := remote.Delete(ctx, obj); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+
+			continue
+		}
+
+		log.V(5).Info("decoupling remote object", "name", obj.GetName(), "namespace", obj.GetNamespace())
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := remote.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+
+			if err := i.DecoupleTenant(obj, remoteTenant); err != nil {
+				return err
+			}
+
+			return remote.Update(ctx, obj)
+		})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+
+	// Decoupled ServiceAccounts keep their access to the tenant
+	if decouple {
+		return nil
+	}
+
+	return i.removeServiceAccountOwner(ctx, log, remote, remoteTenant, namespace, tenant.Name)
+}
+
+// Writes the argo cluster secret for the tenant on a remote cluster. The server is verified with the certificate
+// authority of the kubeconfig
+func (i *TenancyController) reflectRemoteClusterSecret(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	cluster *v1alpha1.ControllerRemoteCluster,
+	remoteConfig *rest.Config,
+	destination *argocdv1alpha1.ApplicationDestination,
+	token string,
+) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      destination.Name,
+			Namespace: i.settings(tenant).Argo.Namespace,
+		},
+	}
+
+	err := i.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	// Handle Force, if an object already exists with the same name
+	if !meta.HasTenantOwnerReference(secret, tenant) {
+		if !i.ForceTenant(tenant) && !k8serrors.IsNotFound(err) {
+			log.V(5).Info("cluster secret already present, not overriding", "secret", secret.Name, "namespace", secret.Namespace)
+
+			return ccaerrrors.NewObjectAlreadyExistsError(secret)
+		}
+	}
+
+	config, err := json.Marshal(argocdv1alpha1.ClusterConfig{
+		BearerToken: token,
+		TLSClientConfig: argocdv1alpha1.TLSClientConfig{
+			Insecure: remoteConfig.TLSClientConfig.Insecure,
+			CAData:   remoteConfig.TLSClientConfig.CAData,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal secret data: %w", err)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, secret, func() error {
+		labels := meta.WithTranslatorTrackingLabels(secret, tenant)
+		labels[argo.SecretTypeLabel] = argo.SecretTypeCluster
+		labels[meta.ManagedClusterLabel] = cluster.Name
+		secret.SetLabels(labels)
+
+		secret.Type = corev1.SecretTypeOpaque
+		secret.Data = map[string][]byte{
+			"name":    []byte(destination.Name),
+			"project": []byte(meta.TenantProjectName(tenant)),
+			"server":  []byte(destination.Server),
+			"config":  config,
+		}
+
+		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), secret, tenant)
+	})
+
+	return err
+}
+
+// Removes (or decouples) the argo cluster secrets of remote clusters which are no longer desired
+func (i *TenancyController) lifecycleRemoteClusterSecrets(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	desired map[string]struct{},
+	decouple bool,
+) error {
+	secrets := &corev1.SecretList{}
+	if err := i.Client.List(ctx, secrets,
+		client.InNamespace(i.settings(tenant).Argo.Namespace),
+		client.MatchingLabels{meta.ManagedTenantLabel: tenant.Name},
+		client.HasLabels{meta.ManagedClusterLabel},
+	); err != nil {
+		return err
+	}
+
+	for idx := range secrets.Items {
+		secret := &secrets.Items[idx]
+		if _, ok := desired[secret.Name]; ok {
+			continue
+		}
+
+		if !decouple {
+			log.V(5).Info("removing cluster secret", "secret", secret.Name)
+			if err := i.Client.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+
+			continue
+		}
+
+		log.V(5).Info("decoupling cluster secret", "secret", secret.Name)
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := i.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+				return err
+			}
+
+			if err := i.DecoupleTenant(secret, tenant); err != nil {
+				return err
+			}
+
+			return i.Client.Update(ctx, secret)
+		})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+
+	return nil
+}
+
+// Namespace for the ServiceAccount of the tenant on a remote cluster
+func (i *TenancyController) remoteServiceAccountNamespace(
+	tenant *capsulev1beta2.Tenant,
+	cluster *v1alpha1.ControllerRemoteCluster,
+) string {
+	if ns := meta.TenantServiceAccountNamespace(tenant); ns != "" {
+		return ns
+	}
+
+	if cluster.ServiceAccountNamespace != "" {
+		return cluster.ServiceAccountNamespace
+	}
+
+	return i.settings(tenant).Proxy.ServiceAccountNamespace
+}
+
+// Client for the remote cluster, built from the referenced kubeconfig
+func (i *TenancyController) remoteClient(
+	ctx context.Context,
+	cluster *v1alpha1.ControllerRemoteCluster,
+) (client.Client, *rest.Config, error) {
+	secret := &corev1.Secret{}
+	err := i.uncachedReader().Get(ctx, client.ObjectKey{Name: cluster.KubeConfig.Name, Namespace: cluster.KubeConfig.Namespace}, secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kubeconfig: %w", err)
+	}
+
+	i.remotesLock.Lock()
+	defer i.remotesLock.Unlock()
+
+	if i.remotes == nil {
+		i.remotes = make(map[string]remoteClient)
+	}
+
+	version := string(secret.UID) + "/" + secret.ResourceVersion + "/" + cluster.KubeConfig.Key
+	if cached, ok := i.remotes[cluster.Name]; ok && cached.version == version {
+		return cached.client, cached.config, nil
+	}
+
+	kubeconfig, ok := secret.Data[cluster.KubeConfig.Key]
+	if !ok {
+		return nil, nil, fmt.Errorf("kubeconfig secret %s/%s has no key %s", secret.Namespace, secret.Name, cluster.KubeConfig.Key)
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+
+	// The certificate authority is written to the argo cluster secret, referenced files are inlined
+	if err := rest.LoadTLSFiles(config); err != nil {
+		return nil, nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+
+	c, err := client.New(config, client.Options{Scheme: i.Client.Scheme()})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	i.remotes[cluster.Name] = remoteClient{version: version, client: c, config: config}
+
+	return c, config, nil
+}
@@ -0,0 +1,333 @@
+package tenant
+
+import (
+	"context"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Delay until the tenant is reconciled again, while the previous appproject still has applications
+const projectRenameRequeue = 10 * time.Second
+
+// Migrates the tenant from the previously applied appproject to the current appproject, when the project name
+// changed. The previous appproject and its policy are removed once no applications are left in it. The
+// applications are re-pointed to the current appproject when enabled on the tenant.
+func (i *TenancyController) reconcileArgoProjectRename(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	translators []*v1alpha1.ArgoTranslator,
+) error {
+	previous := meta.TenantManagedProject(tenant)
+	current := meta.TenantProjectName(tenant)
+
+	if previous == current {
+		return nil
+	}
+
+	// Nothing was applied yet
+	if previous == "" {
+		if len(translators) == 0 {
+			return nil
+		}
+
+		return i.recordManagedProject(ctx, tenant, current)
+	}
+
+	log.V(3).Info("appproject name changed", "from", previous, "to", current)
+
+	appProject := &argocdv1alpha1.AppProject{}
+	err := i.Client.Get(ctx, client.ObjectKey{Name: previous, Namespace: i.settings(tenant).Argo.Namespace}, appProject)
+
+	removePolicy := true
+	switch {
+	case k8serrors.IsNotFound(err):
+		log.V(5).Info("previous appproject already absent", "appproject", previous)
+	case err != nil:
+		return err
+	case !meta.HasTenantOwnerReference(appProject, tenant):
+		log.V(3).Info("previous appproject not owned by tenant, not removing", "appproject", previous)
+
+		removePolicy = false
+	default:
+		removePolicy, err = i.renameArgoProject(ctx, log, tenant, appProject, current, len(translators) > 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Remove the policy of the previous appproject
+	if removePolicy {
+		if err := i.reflectArgoProjectPolicy(ctx, log, tenant, argo.ProjectPolicyName(previous), ""); err != nil {
+			return err
+		}
+	}
+
+	if len(translators) == 0 {
+		return i.recordManagedProject(ctx, tenant, "")
+	}
+
+	return i.recordManagedProject(ctx, tenant, current)
+}
+
+// Removes the previous appproject of the tenant once it is empty. When the appproject is shared with other tenants
+// only the contributions of the tenant are removed. Returns if the policy of the appproject can be removed.
+func (i *TenancyController) renameArgoProject(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	appProject *argocdv1alpha1.AppProject,
+	current string,
+	repoint bool,
+) (bool, error) {
+	// Other tenants still share the previous appproject
+	for _, ownerRef := range appProject.GetOwnerReferences() {
+		if ownerRef.Kind != "Tenant" || ownerRef.UID == tenant.UID {
+			continue
+		}
+
+		log.V(5).Info("leaving previous appproject", "appproject", appProject.Name)
+
+		_, err := controllerutil.CreateOrPatch(ctx, i.Client, appProject, func() error {
+			argo.ReplaceManagedDestinations(appProject, []string{tenant.Name}, nil)
+			argo.ReplaceManagedDestinations(appProject, meta.GetAnnotationList(appProject, meta.ManagedDestinationsAnnotation(tenant.Name)), nil)
+			meta.SetAnnotationList(appProject, meta.ManagedDestinationsAnnotation(tenant.Name), nil)
+
+			meta.RemoveTenantOwnerReferencesByName(appProject, tenant.Name)
+			controllerutil.RemoveFinalizer(appProject, meta.ProjectMemberFinalizer(tenant.Name))
+
+			return nil
+		})
+
+		return false, err
+	}
+
+	if repoint && meta.TenantRenameApplications(tenant) {
+		if err := i.repointArgoApplications(ctx, log, appProject.Name, current); err != nil {
+			return false, err
+		}
+	}
+
+	applications, err := i.projectApplications(ctx, appProject.Name)
+	if err != nil {
+		return false, err
+	}
+
+	if len(applications) > 0 {
+		log.V(3).Info("previous appproject still has applications", "appproject", appProject.Name, "applications", len(applications))
+
+		return false, ccaerrrors.NewProjectRenameInProgressError(appProject.Name, current, len(applications))
+	}
+
+	log.V(5).Info("removing previous appproject", "appproject", appProject.Name)
+
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(appProject), appProject); err != nil {
+			return err
+		}
+
+		meta.RemoveTranslatingFinalizers(appProject)
+		for _, member := range meta.GetProjectMemberFinalizers(appProject) {
+			controllerutil.RemoveFinalizer(appProject, meta.ProjectMemberFinalizer(member))
+		}
+
+		return i.Client.Update(ctx, appProject)
+	})
+	if err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	if err := i.Client.Delete(ctx, appProject); err != nil && !k8serrors.IsNotFound(err) {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Applications (in any namespace) which are assigned to the appproject
+func (i *TenancyController) projectApplications(
+	ctx context.Context,
+	project string,
+) (applications []*argocdv1alpha1.Application, err error) {
+	list := &argocdv1alpha1.ApplicationList{}
+	if err := i.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	for idx := range list.Items {
+		if list.Items[idx].Spec.Project == project {
+			applications = append(applications, &list.Items[idx])
+		}
+	}
+
+	return applications, nil
+}
+
+// ApplicationSets (in any namespace) which generate Applications for the appproject
+func (i *TenancyController) projectApplicationSets(
+	ctx context.Context,
+	project string,
+) (applicationSets []*argocdv1alpha1.ApplicationSet, err error) {
+	list := &argocdv1alpha1.ApplicationSetList{}
+	if err := i.Client.List(ctx, list); err != nil {
+		return nil, err
+	}
+
+	for idx := range list.Items {
+		if list.Items[idx].Spec.Template.Spec.Project == project {
+			applicationSets = append(applicationSets, &list.Items[idx])
+		}
+	}
+
+	return applicationSets, nil
+}
+
+// Re-points the Applications and ApplicationSets of the previous appproject to the current appproject
+func (i *TenancyController) repointArgoApplications(
+	ctx context.Context,
+	log logr.Logger,
+	previous string,
+	current string,
+) error {
+	applications, err := i.projectApplications(ctx, previous)
+	if err != nil {
+		return err
+	}
+
+	for _, application := range applications {
+		log.V(5).Info("re-pointing application", "application", application.Name, "namespace", application.Namespace, "appproject", current)
+
+		_, err := controllerutil.CreateOrPatch(ctx, i.Client, application, func() error {
+			application.Spec.Project = current
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// ApplicationSets would revert the generated Applications
+	applicationSets, err := i.projectApplicationSets(ctx, previous)
+	if err != nil {
+		return err
+	}
+
+	for _, applicationSet := range applicationSets {
+		log.V(5).Info("re-pointing applicationset", "applicationset", applicationSet.Name, "namespace", applicationSet.Namespace, "appproject", current)
+
+		_, err := controllerutil.CreateOrPatch(ctx, i.Client, applicationSet, func() error {
+			applicationSet.Spec.Template.Spec.Project = current
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Records the applied appproject name on the tenant
+func (i *TenancyController) recordManagedProject(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	project string,
+) error {
+	if meta.TenantManagedProject(tenant) == project {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(tenant), tenant); err != nil {
+			return err
+		}
+
+		annotations := tenant.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+
+		if project == "" {
+			delete(annotations, meta.AnnotationManagedProject)
+		} else {
+			annotations[meta.AnnotationManagedProject] = project
+		}
+
+		tenant.SetAnnotations(annotations)
+
+		return i.Client.Update(ctx, tenant)
+	})
+}
+
+// Handler to reconcile the Tenant which is renaming the appproject of the Application
+func (i *TenancyController) ProjectRenameHandler() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		application, ok := a.(*argocdv1alpha1.Application)
+		if !ok || application.Spec.Project == "" {
+			return nil
+		}
+
+		tenants := &capsulev1beta2.TenantList{}
+		if err := i.Client.List(ctx, tenants); err != nil {
+			i.Log.Error(err, "Failed to list tenants for reconciliation")
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for idx := range tenants.Items {
+			tenant := &tenants.Items[idx]
+			if meta.TenantManagedProject(tenant) != application.Spec.Project ||
+				meta.TenantProjectName(tenant) == application.Spec.Project ||
+				!i.managesTenant(tenant) {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name: tenant.Name,
+				},
+			})
+		}
+
+		return requests
+	})
+}
+
+// Only consider applications leaving an appproject, either by changing the project or by being deleted
+func applicationProjectPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			old, ok := e.ObjectOld.(*argocdv1alpha1.Application)
+			if !ok {
+				return false
+			}
+
+			app, ok := e.ObjectNew.(*argocdv1alpha1.Application)
+			if !ok {
+				return false
+			}
+
+			return old.Spec.Project != app.Spec.Project
+		},
+	}
+}
@@ -0,0 +1,196 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	ccaerrrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reflects the repository secrets from the tenant namespaces into the argo namespace, scoped to the tenant project
+func (i *TenancyController) reconcileArgoRepositories(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	appProject *argocdv1alpha1.AppProject,
+) error {
+	desired := make(map[string]struct{})
+
+	var errs []error
+	if i.settings(tenant).Argo.Repositories.Enabled {
+		for _, namespace := range tenant.Status.Namespaces {
+			secrets := &corev1.SecretList{}
+			if err := i.repositoryReader().List(ctx, secrets, client.InNamespace(namespace), client.HasLabels{meta.RepositoryLabel}); err != nil {
+				return err
+			}
+
+			for idx := range secrets.Items {
+				source := &secrets.Items[idx]
+
+				// Invalid sources are not desired, a previously reflected secret is removed with its credentials
+				secretType, err := validateArgoRepository(appProject, source)
+				if err != nil {
+					log.V(5).Info("repository secret is invalid", "secret", source.Namespace+"/"+source.Name, "error", err.Error())
+					errs = append(errs, err)
+
+					continue
+				}
+
+				name, err := i.reflectArgoRepository(ctx, log, tenant, appProject, source, secretType)
+				if err != nil {
+					errs = append(errs, err)
+				}
+
+				// Keep the reflected secret, even if it could not be updated
+				desired[name] = struct{}{}
+			}
+		}
+	}
+
+	if err := i.lifecycleArgoRepositories(ctx, log, tenant, desired, false); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// Validates a repository secret against the appproject, returns the argo secret-type of the repository
+func validateArgoRepository(appProject *argocdv1alpha1.AppProject, source *corev1.Secret) (string, error) {
+	secretType := source.Labels[meta.RepositoryLabel]
+	if !argo.IsRepositorySecretType(secretType) {
+		return "", fmt.Errorf("secret %s/%s has invalid repository type %q", source.Namespace, source.Name, secretType)
+	}
+
+	url := string(source.Data["url"])
+	if url == "" {
+		return "", fmt.Errorf("secret %s/%s has no repository url", source.Namespace, source.Name)
+	}
+
+	if !argo.RepositoryPermitted(appProject, secretType, url) {
+		return "", fmt.Errorf("repository %s from secret %s/%s is not permitted by project %s", url, source.Namespace, source.Name, appProject.Name)
+	}
+
+	return secretType, nil
+}
+
+// Reflects a single valid repository secret, returns the name of the reflected secret
+func (i *TenancyController) reflectArgoRepository(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	appProject *argocdv1alpha1.AppProject,
+	source *corev1.Secret,
+	secretType string,
+) (string, error) {
+	name := argo.RepositorySecretName(source.Namespace, source.Name)
+
+	target := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: i.settings(tenant).Argo.Namespace,
+		},
+	}
+
+	err := i.getManagedObject(ctx, target)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return name, err
+	}
+
+	// Handle Force, if an object already exists with the same name
+	if !meta.HasTenantOwnerReference(target, tenant) {
+		if !i.ForceTenant(tenant) && !k8serrors.IsNotFound(err) {
+			log.V(5).Info("repository secret already present, not overriding", "secret", target.Name, "namespace", target.Namespace)
+
+			return name, ccaerrrors.NewObjectAlreadyExistsError(target)
+		}
+	}
+
+	log.V(7).Info("reflecting repository", "source", source.Namespace+"/"+source.Name, "secret", target.Name)
+
+	_, err = i.createOrUpdateManagedObject(ctx, target, func() error {
+		labels := meta.WithTranslatorTrackingLabels(target, tenant)
+		labels[argo.SecretTypeLabel] = secretType
+		target.SetLabels(labels)
+
+		if target.Annotations == nil {
+			target.Annotations = make(map[string]string)
+		}
+		target.Annotations[meta.AnnotationSource] = source.Namespace + "/" + source.Name
+
+		target.Data = make(map[string][]byte, len(source.Data)+1)
+		for key, value := range source.Data {
+			target.Data[key] = value
+		}
+		target.Data["project"] = []byte(appProject.Name)
+
+		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), target, tenant)
+	})
+
+	return name, err
+}
+
+// Removes (or decouples) the reflected repository secrets which are no longer desired
+func (i *TenancyController) lifecycleArgoRepositories(
+	ctx context.Context,
+	log logr.Logger,
+	tenant *capsulev1beta2.Tenant,
+	desired map[string]struct{},
+	decouple bool,
+) error {
+	secrets := &corev1.SecretList{}
+	if err := i.Client.List(ctx, secrets,
+		client.InNamespace(i.settings(tenant).Argo.Namespace),
+		client.MatchingLabels{meta.ManagedTenantLabel: tenant.Name},
+	); err != nil {
+		return err
+	}
+
+	for idx := range secrets.Items {
+		secret := &secrets.Items[idx]
+		if !argo.IsRepositorySecretType(secret.Labels[argo.SecretTypeLabel]) || secret.Annotations[meta.AnnotationSource] == "" {
+			continue
+		}
+
+		if _, ok := desired[secret.Name]; ok {
+			continue
+		}
+
+		if !decouple {
+			log.V(5).Info("removing repository secret", "secret", secret.Name)
+			if err := i.Client.Delete(ctx, secret); err != nil && !k8serrors.IsNotFound(err) {
+				return err
+			}
+
+			continue
+		}
+
+		log.V(5).Info("decoupling repository secret", "secret", secret.Name)
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := i.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret); err != nil {
+				return err
+			}
+
+			if err := i.DecoupleTenant(secret, tenant); err != nil {
+				return err
+			}
+
+			return i.Client.Update(ctx, secret)
+		})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+
+	return nil
+}
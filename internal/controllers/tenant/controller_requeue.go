@@ -0,0 +1,61 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Minimum delay of a requested requeue, so a due rotation does not requeue the tenant immediately again
+const minimumRequeue = time.Second
+
+type requeueKey struct{}
+
+// Earliest time at which the tenant must be reconciled again (eg. to rotate tokens before they expire)
+type requeue struct {
+	sync.Mutex
+	at time.Time
+}
+
+// Context collecting the requested requeues of the current reconciliation
+func withRequeue(ctx context.Context) (context.Context, *requeue) {
+	r := &requeue{}
+
+	return context.WithValue(ctx, requeueKey{}, r), r
+}
+
+// Requests a reconciliation of the tenant at the given time. Zero times are ignored
+func requeueAt(ctx context.Context, at time.Time) {
+	r, ok := ctx.Value(requeueKey{}).(*requeue)
+	if !ok || at.IsZero() {
+		return
+	}
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.at.IsZero() || at.Before(r.at) {
+		r.at = at
+	}
+}
+
+// Delay until the next reconciliation, the earlier of the requested requeue and the resync interval
+func (r *requeue) after(now time.Time, resync time.Duration) time.Duration {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.at.IsZero() {
+		return resync
+	}
+
+	delay := r.at.Sub(now)
+	if delay < minimumRequeue {
+		delay = minimumRequeue
+	}
+
+	if resync > 0 && resync < delay {
+		return resync
+	}
+
+	return delay
+}
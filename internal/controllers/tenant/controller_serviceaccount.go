@@ -24,14 +24,8 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 	tenant *capsulev1beta2.Tenant,
 ) (token string, err error) {
 
-	// Get Required default values
-	serviceAccount := tenant.Name
-	namespace := i.Settings.Get().Proxy.ServiceAccountNamespace
-
-	// Verify if ServiceAccount-Namespace is declared on tenant-basis
-	if ns := meta.TenantServiceAccountNamespace(tenant); ns != "" {
-		namespace = ns
-	}
+	// Get Required default values (ServiceAccount-Namespace may be declared on tenant-basis)
+	namespace, serviceAccount := i.settings(tenant).TenantServiceAccount(tenant)
 
 	log.V(7).Info("reconciling serviceaccount", "serviceaccount", serviceAccount, "namespace", namespace)
 
@@ -42,12 +36,7 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 		},
 	}
 
-	err = i.Client.Get(
-		ctx,
-		client.ObjectKey{
-			Name:      accountResource.Name,
-			Namespace: accountResource.Namespace,
-		}, accountResource)
+	err = i.getManagedObject(ctx, accountResource)
 	if err != nil && !k8serrors.IsNotFound(err) {
 		return "", err
 	}
@@ -93,7 +82,7 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 	// Remove ServiceAccount if not enabled
 	if !i.provisionProxyService(tenant) {
 		log.V(7).Info("removing serviceaccount as owner", "serviceaccount", serviceAccount, "namespace", namespace)
-		if err := i.removeServiceAccountOwner(ctx, log, tenant, namespace, serviceAccount); err != nil {
+		if err := i.removeServiceAccountOwner(ctx, log, i.Client, tenant, namespace, serviceAccount); err != nil {
 			return "", err
 		}
 
@@ -108,11 +97,12 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 	log.V(7).Info("ensuring serviceaccount", "serviceaccount", serviceAccount, "namespace", namespace)
 
 	// Create ServiceAccount
-	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, accountResource, func() (err error) {
+	_, err = i.createOrUpdateManagedObject(ctx, accountResource, func() (err error) {
 		if accountResource.ObjectMeta.Labels == nil {
 			accountResource.ObjectMeta.Labels = make(map[string]string)
 		}
 		accountResource.ObjectMeta.Labels = meta.TranslatorTrackingLabels(tenant)
+		stampInputHash(ctx, accountResource)
 
 		return meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), accountResource, tenant)
 	})
@@ -121,7 +111,7 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 	}
 
 	// Add ServiceAccount to Tenant-Spec
-	err = i.addServiceAccountOwner(ctx, log, tenant, namespace, serviceAccount)
+	err = i.addServiceAccountOwner(ctx, log, i.Client, tenant, namespace, serviceAccount)
 	if err != nil {
 		return "", err
 	}
@@ -135,7 +125,7 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 	}
 
 	// Attempt to fetch the existing secret to ensure ResourceVersion is set if it exists
-	err = i.Client.Get(ctx, client.ObjectKey{Name: serviceAccount, Namespace: namespace}, tokenResource)
+	err = i.getManagedObject(ctx, tokenResource)
 	if err != nil && !k8serrors.IsNotFound(err) {
 		// Return any error other than NotFound
 		return "", err
@@ -148,13 +138,14 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 		"namespace", namespace)
 
 	// Create Account Token
-	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, tokenResource, func() (err error) {
+	_, err = i.createOrUpdateManagedObject(ctx, tokenResource, func() (err error) {
 		tokenResource.ObjectMeta.Labels = meta.TranslatorTrackingLabels(tenant)
 
 		if tokenResource.ObjectMeta.Annotations == nil {
 			tokenResource.ObjectMeta.Annotations = make(map[string]string)
 		}
 		tokenResource.ObjectMeta.Annotations["kubernetes.io/service-account.name"] = serviceAccount
+		stampInputHash(ctx, tokenResource)
 
 		if err := meta.AddDynamicTenantOwnerReference(ctx, i.Client.Scheme(), accountResource, tenant); err != nil {
 			return err
@@ -202,6 +193,7 @@ func (i *TenancyController) reconcileArgoServiceAccount(
 func (i *TenancyController) addServiceAccountOwner(
 	ctx context.Context,
 	log logr.Logger,
+	c client.Client,
 	tenant *capsulev1beta2.Tenant,
 	namespace string,
 	name string,
@@ -220,11 +212,11 @@ func (i *TenancyController) addServiceAccountOwner(
 	}
 
 	err = retry.RetryOnConflict(retry.DefaultBackoff, func() (conflict error) {
-		_ = i.Client.Get(ctx, types.NamespacedName{Name: tenant.Name}, tenant)
+		_ = c.Get(ctx, types.NamespacedName{Name: tenant.Name}, tenant)
 		log.V(5).Info("adding serviceaccount as owner")
 
 		tenant.Spec.Owners = append(tenant.Spec.Owners, owner)
-		if conflict = i.Client.Update(ctx, tenant); err != nil {
+		if conflict = c.Update(ctx, tenant); err != nil {
 			return err
 		}
 		return
@@ -237,6 +229,7 @@ func (i *TenancyController) addServiceAccountOwner(
 func (i *TenancyController) removeServiceAccountOwner(
 	ctx context.Context,
 	log logr.Logger,
+	c client.Client,
 	tenant *capsulev1beta2.Tenant,
 	namespace string,
 	name string,
@@ -262,7 +255,7 @@ func (i *TenancyController) removeServiceAccountOwner(
 
 	// Retry logic to avoid conflicts
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-		if err := i.Client.Get(ctx, types.NamespacedName{Name: tenant.Name}, tenant); err != nil {
+		if err := c.Get(ctx, types.NamespacedName{Name: tenant.Name}, tenant); err != nil {
 			return err
 		}
 
@@ -277,6 +270,6 @@ func (i *TenancyController) removeServiceAccountOwner(
 		tenant.Spec.Owners = owners
 
 		// Update the tenant resource
-		return i.Client.Update(ctx, tenant)
+		return c.Update(ctx, tenant)
 	})
 }
@@ -0,0 +1,245 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/argo"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Source namespaces for the given tenant (either the namespaces of the tenant or the rendered pattern)
+func (i *TenancyController) tenantSourceNamespaces(tenant *capsulev1beta2.Tenant) ([]string, error) {
+	cfg := i.settings(tenant)
+	if !cfg.Argo.SourceNamespaces.Enabled {
+		return nil, nil
+	}
+
+	if cfg.Argo.SourceNamespaces.Pattern == "" {
+		namespaces := append([]string{}, tenant.Status.Namespaces...)
+		sort.Strings(namespaces)
+
+		return namespaces, nil
+	}
+
+	tmpl, err := template.New("sourceNamespaces").Funcs(tpl.ExtraFuncMap()).Parse(cfg.Argo.SourceNamespaces.Pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing source namespace pattern: %w", err)
+	}
+
+	rendered, err := sandbox.Execute(tmpl, tpl.ConfigContext(cfg.ProxyServiceString(tenant), nil, cfg, tenant, nil, nil))
+	if err != nil {
+		return nil, fmt.Errorf("error executing source namespace pattern: %w", err)
+	}
+
+	pattern := strings.TrimSpace(string(rendered))
+	if pattern == "" {
+		return nil, nil
+	}
+
+	return []string{pattern}, nil
+}
+
+// Reflects the source namespaces of all members on the appproject. Only the namespaces which were added
+// by the controller are removed again.
+func (i *TenancyController) reflectProjectSourceNamespaces(
+	log logr.Logger,
+	appProject *argocdv1alpha1.AppProject,
+	members []projectMember,
+) error {
+	var desired []string
+	for _, member := range members {
+		namespaces, err := i.tenantSourceNamespaces(member.tenant)
+		if err != nil {
+			return err
+		}
+
+		desired = append(desired, namespaces...)
+	}
+
+	var managed []string
+	appProject.Spec.SourceNamespaces, managed = argo.ReplaceManaged(
+		appProject.Spec.SourceNamespaces,
+		meta.GetAnnotationList(appProject, meta.AnnotationManagedSourceNamespaces),
+		desired,
+	)
+
+	meta.SetAnnotationList(appProject, meta.AnnotationManagedSourceNamespaces, managed)
+
+	log.V(7).Info("reflected source namespaces", "appproject", appProject.Name, "namespaces", appProject.Spec.SourceNamespaces)
+
+	return nil
+}
+
+// Delay to collect the changes of several tenants, before the source namespaces are reflected
+const sourceNamespacesDebounce = 2 * time.Second
+
+// Requests to reflect the source namespaces of all instances. Requests of several tenants are combined
+func (i *TenancyController) requestSourceNamespaces() {
+	select {
+	case i.sourceNamespaces <- struct{}{}:
+	default:
+	}
+}
+
+// Reflects the source namespaces of all instances once per request, instead of on every tenant reconcile
+func (i *TenancyController) runSourceNamespaces(ctx context.Context) error {
+	log := i.Log.WithName("source-namespaces")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-i.sourceNamespaces:
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(sourceNamespacesDebounce):
+		}
+
+		if err := i.reflectArgoSourceNamespaces(ctx, log); err != nil {
+			log.Error(err, "failed to reflect source namespaces")
+			i.requestSourceNamespaces()
+		}
+	}
+}
+
+// Keeps the argocd command parameters of all instances up to date with the source namespaces of their tenants
+func (i *TenancyController) reflectArgoSourceNamespaces(
+	ctx context.Context,
+	log logr.Logger,
+) error {
+	tenants := &capsulev1beta2.TenantList{}
+	if err := i.Client.List(ctx, tenants); err != nil {
+		return err
+	}
+
+	for _, instance := range i.Settings.Get().InstanceNames() {
+		cfg, err := i.Settings.Get().ForInstance(instance)
+		if err != nil {
+			return err
+		}
+
+		if err := i.reflectInstanceSourceNamespaces(ctx, log, cfg, instance, tenants.Items); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Keeps the argocd command parameters of an instance up to date with the source namespaces of its tenants
+func (i *TenancyController) reflectInstanceSourceNamespaces(
+	ctx context.Context,
+	log logr.Logger,
+	cfg *v1alpha1.ArgoAddonSpec,
+	instance string,
+	tenants []capsulev1beta2.Tenant,
+) error {
+	// Collect the namespaces from all managed tenants of the instance
+	var desired []string
+	if cfg.Argo.SourceNamespaces.Enabled {
+		for _, tnt := range tenants {
+			tenant := tnt
+			if !tenant.ObjectMeta.DeletionTimestamp.IsZero() || !controllerutil.ContainsFinalizer(&tenant, meta.Finalizer()) {
+				continue
+			}
+
+			if meta.TenantManagedInstance(&tenant) != instance {
+				continue
+			}
+
+			namespaces, err := i.tenantSourceNamespaces(&tenant)
+			if err != nil {
+				return err
+			}
+
+			desired = append(desired, namespaces...)
+		}
+
+		sort.Strings(desired)
+	}
+
+	desiredAppSets := desired
+	if !cfg.Argo.SourceNamespaces.ApplicationSets {
+		desiredAppSets = nil
+	}
+
+	configmap := &corev1.ConfigMap{}
+	err := i.Client.Get(ctx, client.ObjectKey{
+		Name:      cfg.Argo.CmdParamsConfigMap,
+		Namespace: cfg.Argo.Namespace}, configmap)
+	if err != nil {
+		// Nothing to clean up
+		if k8serrors.IsNotFound(err) && !cfg.Argo.SourceNamespaces.Enabled {
+			return nil
+		}
+
+		return err
+	}
+
+	log.V(7).Info("reflecting source namespaces", "configmap", configmap.Name, "namespaces", desired)
+
+	var result controllerutil.OperationResult
+	err = retry.RetryOnConflict(retry.DefaultBackoff, func() (conflictErr error) {
+		result, conflictErr = controllerutil.CreateOrUpdate(ctx, i.Client, configmap, func() error {
+			if configmap.Data == nil {
+				configmap.Data = make(map[string]string)
+			}
+
+			reflectNamespaceParam(configmap, argo.CmdParamApplicationNamespaces, desired)
+			reflectNamespaceParam(configmap, argo.CmdParamApplicationSetNamespaces, desiredAppSets)
+
+			return nil
+		})
+
+		return
+	})
+	if err != nil {
+		return err
+	}
+
+	// Argo CD reads the command parameters on startup only
+	if result == controllerutil.OperationResultUpdated && i.Recorder != nil {
+		i.Recorder.Event(configmap, corev1.EventTypeNormal, "RestartRequired",
+			"Source namespaces changed, restart the argocd server, application controller and applicationset controller to apply them")
+	}
+
+	return nil
+}
+
+// Replaces the managed namespaces of a command parameter
+func reflectNamespaceParam(configmap *corev1.ConfigMap, param string, desired []string) {
+	tracking := meta.ManagedParamAnnotation(param)
+
+	namespaces, managed := argo.ReplaceManaged(
+		argo.ParseNamespaceParam(configmap.Data[param]),
+		meta.GetAnnotationList(configmap, tracking),
+		desired,
+	)
+
+	if len(namespaces) == 0 {
+		delete(configmap.Data, param)
+	} else {
+		configmap.Data[param] = argo.FormatNamespaceParam(namespaces)
+	}
+
+	meta.SetAnnotationList(configmap, tracking, managed)
+}
@@ -1,11 +1,51 @@
 package tenant
 
 import (
+	"context"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
 	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
+// Settings for the argocd instance which manages the tenant
+func (i *TenancyController) settings(tenant *capsulev1beta2.Tenant) *v1alpha1.ArgoAddonSpec {
+	return i.Settings.Get().ForTenant(tenant)
+}
+
+// Renderer for the templates of the translator. The context includes the values for the tenant, the funcmap the
+// template libraries of the translator and lookup
+func (i *TenancyController) translatorRenderer(
+	ctx context.Context,
+	tenant *capsulev1beta2.Tenant,
+	translator *v1alpha1.ArgoTranslator,
+) (*tpl.Renderer, error) {
+	// The values ConfigMaps in the tenant namespaces are not cached
+	values, err := tpl.TranslatorValues(ctx, i.uncachedReader(), translator, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	libraries, err := tpl.TranslatorLibraries(ctx, i.Client, translator)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := tpl.TenantObjects(ctx, i.Client, i.settings(tenant), tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	funcMap := i.tenantLookup(ctx, i.Log.WithValues("tenant", tenant.Name), tenant).AddTo(tpl.ExtraFuncMap(libraries...))
+	tplCtx := tpl.ConfigContext(i.settings(tenant).ProxyServiceString(tenant), translator, i.settings(tenant), tenant, values, objects)
+
+	return tpl.NewRenderer(translator, tenant, libraries, tplCtx, funcMap), nil
+}
+
 // Decouple a Tenant from an Object
 func (i *TenancyController) DecoupleTenant(obj client.Object, tenant *capsulev1beta2.Tenant) (err error) {
 	if err = meta.RemoveDynamicTenantOwnerReference(obj, tenant); err != nil {
@@ -20,7 +60,7 @@ func (i *TenancyController) DecoupleTenant(obj client.Object, tenant *capsulev1b
 
 // Determines if the proxy service should be registered
 func (i *TenancyController) ForceTenant(tenant *capsulev1beta2.Tenant) bool {
-	return meta.ProccessBoolean(tenant.GetAnnotations()[meta.AnnotationForce], i.Settings.Get().Force)
+	return meta.ProccessBoolean(tenant.GetAnnotations()[meta.AnnotationForce], i.settings(tenant).Force)
 }
 
 // Determines if the proxy service should be registered
@@ -28,9 +68,65 @@ func (i *TenancyController) provisionProxyService(tenant *capsulev1beta2.Tenant)
 	provision = false
 
 	// Check if the tenant is registered for the proxy
-	if i.Settings.Get().Proxy.Enabled && meta.TenantProxyRegister(tenant) {
+	if i.settings(tenant).Proxy.Enabled && meta.TenantProxyRegister(tenant) {
 		provision = true
 	}
 
 	return
 }
+
+// Reader for objects outside of the manager cache, falls back to the client when no reader is configured
+func (i *TenancyController) uncachedReader() client.Reader {
+	if i.APIReader != nil {
+		return i.APIReader
+	}
+
+	return i.Client
+}
+
+// Gets a managed object, objects which are not found in the cache are read from the API server. Objects outside
+// of the cache scope (eg. unlabeled objects in namespaces which are not cached) are only visible there
+func (i *TenancyController) getManagedObject(ctx context.Context, obj client.Object) error {
+	err := i.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj)
+	if !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return i.uncachedReader().Get(ctx, client.ObjectKeyFromObject(obj), obj)
+}
+
+// Creates or updates a managed object. The creation of an object outside of the cache scope fails with
+// AlreadyExists, the object is then updated from the state of the API server
+func (i *TenancyController) createOrUpdateManagedObject(
+	ctx context.Context,
+	obj client.Object,
+	f controllerutil.MutateFn,
+) (controllerutil.OperationResult, error) {
+	result, err := controllerutil.CreateOrUpdate(ctx, i.Client, obj, f)
+	if !k8serrors.IsAlreadyExists(err) {
+		return result, err
+	}
+
+	if err := i.uncachedReader().Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	if err := f(); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	if err := i.Client.Update(ctx, obj); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	return controllerutil.OperationResultUpdated, nil
+}
+
+// Reader for the repository secrets in the tenant namespaces
+func (i *TenancyController) repositoryReader() client.Reader {
+	if i.repositories != nil {
+		return i.repositories
+	}
+
+	return i.uncachedReader()
+}
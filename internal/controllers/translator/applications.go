@@ -0,0 +1,99 @@
+package translator
+
+import (
+	"context"
+
+	argocdapi "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	"github.com/go-logr/logr"
+	configv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// List all Applications and ApplicationSets matching the given labels
+func ListTranslatedApplications(
+	ctx context.Context,
+	c client.Client,
+	selector client.MatchingLabels,
+) (objs []client.Object, err error) {
+	apps := &argocdapi.ApplicationList{}
+	if err = c.List(ctx, apps, selector); err != nil {
+		return nil, err
+	}
+
+	for idx := range apps.Items {
+		objs = append(objs, &apps.Items[idx])
+	}
+
+	appsets := &argocdapi.ApplicationSetList{}
+	if err = c.List(ctx, appsets, selector); err != nil {
+		return nil, err
+	}
+
+	for idx := range appsets.Items {
+		objs = append(objs, &appsets.Items[idx])
+	}
+
+	return objs, nil
+}
+
+// Removes the translator finalizers from a translated Application or ApplicationSet and deletes it
+func RemoveTranslatedApplication(
+	ctx context.Context,
+	c client.Client,
+	log logr.Logger,
+	obj client.Object,
+) error {
+	log.V(5).Info("removing translated application", "name", obj.GetName(), "namespace", obj.GetNamespace())
+
+	if meta.ContainsTranslatorFinalizer(obj) {
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			if err := c.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+				return err
+			}
+
+			meta.RemoveTranslatingFinalizers(obj)
+
+			return c.Update(ctx, obj)
+		})
+		if err != nil {
+			return client.IgnoreNotFound(err)
+		}
+	}
+
+	if err := c.Delete(ctx, obj); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// Remove all Applications and ApplicationSets provisioned by the translator
+func RemoveTranslatorApplications(
+	ctx context.Context,
+	c client.Client,
+	log logr.Logger,
+	translator *configv1alpha1.ArgoTranslator,
+) error {
+	objs, err := ListTranslatedApplications(ctx, c, client.MatchingLabels{
+		meta.ManagedTranslatorLabel: translator.Name,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		if !controllerutil.ContainsFinalizer(obj, meta.TranslatorFinalizer(translator.Name)) {
+			continue
+		}
+
+		if err := RemoveTranslatedApplication(ctx, c, log, obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
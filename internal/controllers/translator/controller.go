@@ -6,23 +6,31 @@ import (
 	argocdapi "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/go-logr/logr"
 	configv1alpha1 "github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/expression"
 	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	"github.com/peak-scale/capsule-argo-addon/internal/reflection"
 	"github.com/peak-scale/capsule-argo-addon/internal/stores"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	tpl "github.com/peak-scale/capsule-argo-addon/internal/template"
+	rendercache "github.com/peak-scale/capsule-argo-addon/internal/template/cache"
 )
 
 var _ reconcile.Reconciler = &TranslatorController{}
@@ -34,14 +42,18 @@ type TranslatorController struct {
 	Log      logr.Logger
 	Settings *stores.ConfigStore
 	requeue  chan event.GenericEvent
+
+	// Options the controller was set up with
+	options configv1alpha1.ControllerOptions
 }
 
 func (i *TranslatorController) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
 	// Initialize Channel
 	i.requeue = make(chan event.GenericEvent)
+	i.options = i.Settings.Get().Controllers.Translator
 
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&configv1alpha1.ArgoTranslator{}).
+		For(&configv1alpha1.ArgoTranslator{}, builder.WithPredicates(i.translatorPredicate())).
 		// Reconcile when an appproject is directly deleted and may include non translated
 		// attributes, which should not be wiped.
 		Watches(&argocdapi.AppProject{},
@@ -61,9 +73,59 @@ func (i *TranslatorController) SetupWithManager(ctx context.Context, mgr ctrl.Ma
 				return requests
 			}),
 		).
+		// Reconcile the translators using a template library
+		Watches(&configv1alpha1.ArgoTemplateLibrary{},
+			handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+				translators := &configv1alpha1.ArgoTranslatorList{}
+				if err := i.Client.List(ctx, translators); err != nil {
+					i.Log.Error(err, "Failed to list translators for reconciliation")
+					return nil
+				}
+
+				var requests []reconcile.Request
+				for _, translator := range translators.Items {
+					if !utils.ContainsString(translator.Spec.Libraries, a.GetName()) {
+						continue
+					}
+
+					requests = append(requests, reconcile.Request{
+						NamespacedName: types.NamespacedName{
+							Name: translator.Name,
+						},
+					})
+				}
+
+				return requests
+			}),
+		).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: i.options.Concurrency(),
+			RateLimiter:             i.options.RateLimiter(),
+		}).
 		Complete(i)
 }
 
+// Only consider translators which are selected by this controller or still carry its finalizer
+func (i *TranslatorController) translatorPredicate() predicate.Funcs {
+	manages := func(obj client.Object) bool {
+		translator, ok := obj.(*configv1alpha1.ArgoTranslator)
+		if !ok {
+			return false
+		}
+
+		return i.Settings.Get().SelectsTranslator(translator) || controllerutil.ContainsFinalizer(translator, meta.Finalizer())
+	}
+
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return manages(e.Object) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return manages(e.Object) },
+		GenericFunc: func(e event.GenericEvent) bool { return manages(e.Object) },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return manages(e.ObjectOld) || manages(e.ObjectNew)
+		},
+	}
+}
+
 func (i *TranslatorController) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
 	log := i.Log.WithValues("translator", request.Name)
 
@@ -72,16 +134,31 @@ func (i *TranslatorController) Reconcile(ctx context.Context, request ctrl.Reque
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	// Translator is handled by another controller, release it
+	if !i.Settings.Get().SelectsTranslator(origin) {
+		if !controllerutil.ContainsFinalizer(origin, meta.Finalizer()) {
+			return ctrl.Result{}, nil
+		}
+
+		log.V(3).Info("translator no longer selected, releasing")
+		controllerutil.RemoveFinalizer(origin, meta.Finalizer())
+
+		return ctrl.Result{}, i.Client.Update(ctx, origin)
+	}
+
 	// Finalize Dependencies
 	if !origin.ObjectMeta.DeletionTimestamp.IsZero() {
-		if controllerutil.ContainsFinalizer(origin, meta.ControllerFinalizer) {
+		if controllerutil.ContainsFinalizer(origin, meta.Finalizer()) {
 			log.V(5).Info("finalizing translator")
 			err := i.finalize(ctx, log, origin)
 			if err != nil {
 				return ctrl.Result{}, err
 			}
 
-			controllerutil.RemoveFinalizer(origin, meta.ControllerFinalizer)
+			expression.Forget(origin.UID)
+			rendercache.Forget(origin.UID)
+
+			controllerutil.RemoveFinalizer(origin, meta.Finalizer())
 			err = retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 				if err := i.Client.Update(ctx, origin); err != nil {
 					return err
@@ -118,6 +195,16 @@ func (i *TranslatorController) Reconcile(ctx context.Context, request ctrl.Reque
 		}
 	}
 
+	// Surface compile errors of the match expression
+	if err := i.reconcileMatchExpression(ctx, log, origin); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Surface missing or invalid template libraries
+	if err := i.reconcileTemplateLibraries(ctx, log, origin); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Update Status if necessary
 	//err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 	//	_, err = controllerutil.CreateOrUpdate(ctx, i.Client, origin, func() error {
@@ -130,8 +217,8 @@ func (i *TranslatorController) Reconcile(ctx context.Context, request ctrl.Reque
 	//	return ctrl.Result{}, err
 	//}
 
-	if !controllerutil.ContainsFinalizer(origin, meta.ControllerFinalizer) {
-		controllerutil.AddFinalizer(origin, meta.ControllerFinalizer)
+	if !controllerutil.ContainsFinalizer(origin, meta.Finalizer()) {
+		controllerutil.AddFinalizer(origin, meta.Finalizer())
 		err := retry.RetryOnConflict(retry.DefaultBackoff, func() (err error) {
 			if err := i.Client.Update(ctx, origin); err != nil {
 				return err
@@ -144,11 +231,88 @@ func (i *TranslatorController) Reconcile(ctx context.Context, request ctrl.Reque
 		}
 	}
 
-	return ctrl.Result{}, nil
+	// Reconcile again after the resync interval, even if nothing changed
+	return ctrl.Result{RequeueAfter: i.options.Resync(0)}, nil
+
+}
+
+// Compiles the match expression and reflects the result on the status of the translator
+func (i *TranslatorController) reconcileMatchExpression(ctx context.Context, log logr.Logger, translator *configv1alpha1.ArgoTranslator) error {
+	var condition *metav1.Condition
+	if translator.Spec.MatchExpression != "" {
+		_, err := translator.CompileMatchExpression()
+		if err != nil {
+			log.Info("match expression failed to compile", "error", err.Error())
+		}
+
+		c := meta.NewMatchExpressionCondition(translator, err)
+		condition = &c
+	}
+
+	return i.reflectCondition(ctx, translator, meta.MatchExpressionCondition, condition)
+}
+
+// Verifies the referenced template libraries and reflects the result on the status of the translator
+func (i *TranslatorController) reconcileTemplateLibraries(ctx context.Context, log logr.Logger, translator *configv1alpha1.ArgoTranslator) error {
+	var condition *metav1.Condition
+	if len(translator.Spec.Libraries) > 0 {
+		libraries, err := tpl.TranslatorLibraries(ctx, i.Client, translator)
+		if err == nil {
+			err = tpl.ValidateLibraries(libraries)
+		}
+
+		if err != nil {
+			log.Info("template libraries invalid", "error", err.Error())
+		}
 
+		c := meta.NewTemplateLibrariesCondition(translator, err)
+		condition = &c
+	}
+
+	return i.reflectCondition(ctx, translator, meta.TemplateLibrariesCondition, condition)
+}
+
+// Sets (or removes, when nil) the condition on the status of the translator, if it changed
+func (i *TranslatorController) reflectCondition(
+	ctx context.Context,
+	translator *configv1alpha1.ArgoTranslator,
+	conditionType string,
+	condition *metav1.Condition,
+) error {
+	current := apimeta.FindStatusCondition(translator.Status.Conditions, conditionType)
+	switch {
+	case condition == nil && current == nil:
+		return nil
+	case condition != nil && current != nil &&
+		current.Status == condition.Status &&
+		current.Message == condition.Message &&
+		current.ObservedGeneration == condition.ObservedGeneration:
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := i.Client.Get(ctx, client.ObjectKeyFromObject(translator), translator); err != nil {
+			return err
+		}
+
+		if condition == nil {
+			apimeta.RemoveStatusCondition(&translator.Status.Conditions, conditionType)
+		} else {
+			apimeta.SetStatusCondition(&translator.Status.Conditions, *condition)
+		}
+
+		translator.CollectStatus()
+
+		return i.Client.Status().Update(ctx, translator)
+	})
 }
 
 func (i *TranslatorController) finalize(ctx context.Context, log logr.Logger, translator *configv1alpha1.ArgoTranslator) error {
+	// Finalize all applications provisioned by the translator
+	if err := RemoveTranslatorApplications(ctx, i.Client, log, translator); err != nil {
+		return err
+	}
+
 	// Finalize all tenants (approjects)
 	tnts := translator.GetTenantNames()
 
@@ -165,7 +329,7 @@ func (i *TranslatorController) finalize(ctx context.Context, log logr.Logger, tr
 		approject := &argocdapi.AppProject{}
 		err = i.Client.Get(ctx, client.ObjectKey{
 			Name:      meta.TenantProjectName(tenant),
-			Namespace: i.Settings.Get().Argo.Namespace,
+			Namespace: i.Settings.Get().ForTenant(tenant).Argo.Namespace,
 		}, approject)
 		if k8serrors.IsNotFound(err) {
 			continue
@@ -175,7 +339,7 @@ func (i *TranslatorController) finalize(ctx context.Context, log logr.Logger, tr
 		}
 
 		// if tenant is no longer managing an approject
-		//if !controllerutil.ContainsFinalizer(tenant, meta.ControllerFinalizer) {
+		//if !controllerutil.ContainsFinalizer(tenant, meta.Finalizer()) {
 		//	continue
 		//}
 
@@ -207,9 +371,34 @@ func RemoveTranslatorForTenant(
 	settings *stores.ConfigStore,
 ) error {
 
+	// Values of the tenant may no longer be resolvable, fall back to the values of the translator
+	values, err := tpl.TranslatorValues(ctx, c, translator, tenant)
+	if err != nil {
+		log.V(5).Info("falling back to translator values", "error", err.Error())
+
+		if values, err = translator.GetValues(); err != nil {
+			return err
+		}
+	}
+
+	libraries, err := tpl.TranslatorLibraries(ctx, c, translator)
+	if err != nil {
+		return err
+	}
+
+	objects, err := tpl.TenantObjects(ctx, c, settings.Get().ForTenant(tenant), tenant)
+	if err != nil {
+		return err
+	}
+
 	// Remove the approject from the tenant
-	cfg, err := translator.Spec.ProjectSettings.GetConfig(
-		tpl.ConfigContext("", translator, settings.Get(), tenant), tpl.ExtraFuncMap())
+	cfg, err := tpl.NewRenderer(
+		translator,
+		tenant,
+		libraries,
+		tpl.ConfigContext("", translator, settings.Get().ForTenant(tenant), tenant, values, objects),
+		tpl.NewLookup(ctx, c, c.RESTMapper(), settings.Get().ForTenant(tenant), nil).AddTo(tpl.ExtraFuncMap(libraries...)),
+	).ProjectConfig()
 	if err != nil {
 		return err
 	}
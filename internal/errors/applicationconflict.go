@@ -0,0 +1,25 @@
+package errors
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplicationConflict represents an error indicating that several translators render the same Application
+type ApplicationConflict struct {
+	Object     client.Object
+	Translator string
+	Conflict   string
+}
+
+// Error implements the error interface for ApplicationConflict
+func (e *ApplicationConflict) Error() string {
+	return fmt.Sprintf("%T %s/%s of translator %s is already rendered by translator %s",
+		e.Object, e.Object.GetNamespace(), e.Object.GetName(), e.Translator, e.Conflict)
+}
+
+// NewApplicationConflictError creates a new ApplicationConflict error
+func NewApplicationConflictError(obj client.Object, translator string, conflict string) error {
+	return &ApplicationConflict{Object: obj, Translator: translator, Conflict: conflict}
+}
@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// ApplicationsPending represents an error indicating that the deletion of a tenant waits for the applications of its appproject
+type ApplicationsPending struct {
+	Project      string
+	Policy       string
+	Applications int
+}
+
+// Error implements the error interface for ApplicationsPending
+func (e *ApplicationsPending) Error() string {
+	return fmt.Sprintf(
+		"deletion policy %s: waiting for %d applications in appproject %s to be removed",
+		e.Policy, e.Applications, e.Project)
+}
+
+// NewApplicationsPendingError creates a new ApplicationsPending error
+func NewApplicationsPendingError(project string, policy string, applications int) error {
+	return &ApplicationsPending{Project: project, Policy: policy, Applications: applications}
+}
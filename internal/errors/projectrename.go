@@ -0,0 +1,24 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// ProjectRenameInProgress represents an error indicating that the previous appproject of a tenant still holds applications
+type ProjectRenameInProgress struct {
+	From         string
+	To           string
+	Applications int
+}
+
+// Error implements the error interface for ProjectRenameInProgress
+func (e *ProjectRenameInProgress) Error() string {
+	return fmt.Sprintf(
+		"renaming appproject %s to %s, waiting for %d applications to leave the previous appproject",
+		e.From, e.To, e.Applications)
+}
+
+// NewProjectRenameInProgressError creates a new ProjectRenameInProgress error
+func NewProjectRenameInProgressError(from string, to string, applications int) error {
+	return &ProjectRenameInProgress{From: from, To: to, Applications: applications}
+}
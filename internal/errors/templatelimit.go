@@ -0,0 +1,21 @@
+package errors
+
+import (
+	"fmt"
+)
+
+// TemplateLimitExceeded represents an error indicating that the rendering of a template exceeded a limit of the sandbox
+type TemplateLimitExceeded struct {
+	Template string
+	Limit    string
+}
+
+// Error implements the error interface for TemplateLimitExceeded
+func (e *TemplateLimitExceeded) Error() string {
+	return fmt.Sprintf("template %s exceeded the %s limit", e.Template, e.Limit)
+}
+
+// NewTemplateLimitExceededError creates a new TemplateLimitExceeded error
+func NewTemplateLimitExceededError(template string, limit string) error {
+	return &TemplateLimitExceeded{Template: template, Limit: limit}
+}
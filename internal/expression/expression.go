@@ -0,0 +1,131 @@
+package expression
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// Name of the variable the tenant is available as in expressions
+	TenantVariable = "tenant"
+
+	// Maximum runtime cost of an evaluation, an expression exceeding the cost is aborted
+	CostLimit uint64 = 1000000
+
+	// Number of comprehension iterations after which the evaluation checks for a timeout
+	InterruptCheckFrequency uint = 100
+
+	// Maximum duration of an evaluation
+	EvaluationTimeout = 100 * time.Millisecond
+)
+
+// Compiled expressions are shared between controllers
+var programs = &Cache{entries: make(map[types.UID]entry)}
+
+// Caches the compiled expression of an object per generation
+type Cache struct {
+	sync.RWMutex
+	entries map[types.UID]entry
+}
+
+type entry struct {
+	generation int64
+	expression string
+	program    cel.Program
+	err        error
+}
+
+// Compiles and type-checks the expression, the expression must evaluate to a bool
+func Compile(expression string) (cel.Program, error) {
+	env, err := cel.NewEnv(cel.Variable(TenantVariable, cel.MapType(cel.StringType, cel.DynType)))
+	if err != nil {
+		return nil, err
+	}
+
+	ast, iss := env.Compile(expression)
+	if iss.Err() != nil {
+		return nil, iss.Err()
+	}
+
+	if ast.OutputType() != cel.BoolType && ast.OutputType() != cel.DynType {
+		return nil, fmt.Errorf("expression must evaluate to bool, got %s", ast.OutputType())
+	}
+
+	return env.Program(ast,
+		cel.CostLimit(CostLimit),
+		cel.InterruptCheckFrequency(InterruptCheckFrequency),
+	)
+}
+
+// Returns the compiled expression for the object, it's only compiled again when the generation changes
+func (c *Cache) Program(uid types.UID, generation int64, expression string) (cel.Program, error) {
+	c.RLock()
+	cached, ok := c.entries[uid]
+	c.RUnlock()
+
+	if ok && cached.generation == generation && cached.expression == expression {
+		return cached.program, cached.err
+	}
+
+	program, err := Compile(expression)
+
+	c.Lock()
+	c.entries[uid] = entry{
+		generation: generation,
+		expression: expression,
+		program:    program,
+		err:        err,
+	}
+	c.Unlock()
+
+	return program, err
+}
+
+// Removes the compiled expression of the object
+func (c *Cache) Forget(uid types.UID) {
+	c.Lock()
+	delete(c.entries, uid)
+	c.Unlock()
+}
+
+// Compiled expression of the object from the shared cache
+func Program(uid types.UID, generation int64, expression string) (cel.Program, error) {
+	return programs.Program(uid, generation, expression)
+}
+
+// Removes the compiled expression of the object from the shared cache
+func Forget(uid types.UID) {
+	programs.Forget(uid)
+}
+
+// Evaluates the program against the tenant. Errors during evaluation (eg. absent fields, exceeded cost or timeout)
+// are returned
+func Matches(program cel.Program, tenant runtime.Object) (bool, error) {
+	obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(tenant)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), EvaluationTimeout)
+	defer cancel()
+
+	out, _, err := program.ContextEval(ctx, map[string]interface{}{
+		TenantVariable: obj,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	matches, ok := out.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression evaluated to %T, expected bool", out.Value())
+	}
+
+	return matches, nil
+}
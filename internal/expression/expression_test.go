@@ -0,0 +1,89 @@
+package expression
+
+import (
+	"testing"
+
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCompile(t *testing.T) {
+	_, err := Compile("tenant.spec.owners.exists(o, o.kind == 'Group')")
+	assert.NoError(t, err)
+
+	_, err = Compile("tenant.spec.owners.exists(o, o.kind ==")
+	assert.Error(t, err, "syntax errors are reported")
+
+	_, err = Compile("size(tenant.metadata.name)")
+	assert.Error(t, err, "expressions must evaluate to bool")
+
+	_, err = Compile("unknown.spec == 'solar'")
+	assert.Error(t, err, "undeclared variables are reported")
+}
+
+func TestMatches(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "solar",
+		},
+		Spec: capsulev1beta2.TenantSpec{
+			Owners: []capsulev1beta2.OwnerSpec{
+				{Kind: "Group", Name: "sre-platform"},
+				{Kind: "User", Name: "alice"},
+			},
+		},
+		Status: capsulev1beta2.TenantStatus{
+			Namespaces: []string{"solar-prod"},
+		},
+	}
+
+	tests := []struct {
+		expression string
+		expected   bool
+		err        bool
+	}{
+		{expression: "tenant.spec.owners.exists(o, o.kind == 'Group' && o.name.startsWith('sre-'))", expected: true},
+		{expression: "tenant.spec.owners.exists(o, o.kind == 'Group' && o.name.startsWith('dev-'))", expected: false},
+		{expression: "size(tenant.status.namespaces) > 0", expected: true},
+		{expression: "tenant.metadata.name == 'solar'", expected: true},
+		{expression: "has(tenant.metadata.labels) && tenant.metadata.labels['env'] == 'prod'", expected: false},
+		{expression: "tenant.metadata.labels['env'] == 'prod'", err: true},
+		{expression: "[0,1,2,3,4,5,6,7,8,9].all(a, [0,1,2,3,4,5,6,7,8,9].all(b, [0,1,2,3,4,5,6,7,8,9].all(c, [0,1,2,3,4,5,6,7,8,9].all(d, [0,1,2,3,4,5,6,7,8,9].all(e, [0,1,2,3,4,5,6,7,8,9].all(f, true))))))", err: true},
+	}
+
+	for _, tt := range tests {
+		program, err := Compile(tt.expression)
+		assert.NoError(t, err, tt.expression)
+
+		matches, err := Matches(program, tenant)
+		if tt.err {
+			assert.Error(t, err, tt.expression)
+			continue
+		}
+
+		assert.NoError(t, err, tt.expression)
+		assert.Equal(t, tt.expected, matches, tt.expression)
+	}
+}
+
+func TestCacheProgram(t *testing.T) {
+	cache := &Cache{entries: make(map[types.UID]entry)}
+
+	first, err := cache.Program("uid", 1, "true")
+	assert.NoError(t, err)
+
+	second, err := cache.Program("uid", 1, "true")
+	assert.NoError(t, err)
+	assert.Equal(t, first, second, "program is compiled once per generation")
+
+	_, err = cache.Program("uid", 2, "true ||")
+	assert.Error(t, err, "program is compiled again for a new generation")
+
+	_, err = cache.Program("uid", 2, "true ||")
+	assert.Error(t, err, "compile errors are cached")
+
+	cache.Forget("uid")
+	assert.Empty(t, cache.entries)
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
@@ -14,6 +15,10 @@ const (
 	// Change the Appproject Name for the tenant
 	AnnotationProjectName = "argo.addons.projectcapsule.dev/name"
 
+	// Annotation (or Label) on Tenant
+	// Tenants with the same group share one appproject, named after the group
+	AnnotationProjectGroup = "argo.addons.projectcapsule.dev/group"
+
 	// Annotation on Tenant
 	// Change the ServiceAccount Namespace for the tenant
 	AnnotationServiceAccountNamespace = "argo.addons.projectcapsule.dev/service-account-namespace"
@@ -33,10 +38,75 @@ const (
 	// Annotation on Tenant
 	// Read-Only mode for the approject (every change from approject ownership is ignored)
 	AnnotationProjectReadOnly = "argo.addons.projectcapsule.dev/read-only"
+
+	// Annotation on Tenant
+	// Comma separated list of translators which are applied to the tenant, regardless of their selector
+	AnnotationTranslators = "argo.addons.projectcapsule.dev/translators"
+
+	// Annotation on Tenant
+	// Comma separated list of translators which are never applied to the tenant
+	AnnotationExcludeTranslators = "argo.addons.projectcapsule.dev/exclude-translators"
+
+	// Annotation on Tenant
+	// Handling of the Applications when the tenant is deleted (Block, Cascade or Orphan)
+	AnnotationDeletionPolicy = "argo.addons.projectcapsule.dev/deletion-policy"
+
+	// Annotation on Tenant
+	// Named ArgoCD instance the tenant is reconciled into
+	AnnotationInstance = "argo.addons.projectcapsule.dev/instance"
+
+	// Annotation on Tenant (managed by the controller)
+	// ArgoCD instance the tenant is currently reconciled into, used to migrate between instances
+	AnnotationManagedInstance = "argo.addons.projectcapsule.dev/managed-instance"
+
+	// Annotation on Tenant (managed by the controller)
+	// AppProject name which was applied for the tenant, used to migrate when the project name changes
+	AnnotationManagedProject = "argo.addons.projectcapsule.dev/managed-name"
+
+	// Annotation on Tenant (managed by the controller)
+	// Rbac target (<kind>/<namespace>/<name>) the policies of the tenant were applied to, used to remove the policies
+	// from the previous target when the target changes
+	AnnotationManagedPolicyTarget = "argo.addons.projectcapsule.dev/managed-policy-target"
+
+	// Annotation on Tenant
+	// Re-point the Applications of the previous appproject to the new appproject when the project name changes
+	AnnotationRenameApplications = "argo.addons.projectcapsule.dev/rename-applications"
+
+	// Annotation on Tenant
+	// Reference (<namespace>/<name>) to a ConfigMap in a namespace of the tenant, which overrides translator values
+	AnnotationValuesFrom = "argo.addons.projectcapsule.dev/values-from"
+
+	// Annotation on Tenant
+	// Prefix for annotations overriding the values of a translator (values.argo.addons.projectcapsule.dev/<translator>)
+	AnnotationValuesPrefix = "values.argo.addons.projectcapsule.dev/"
+
+	// Annotation on managed objects
+	// Source namespaces which were added by the controller (used to only remove what was added)
+	AnnotationManagedSourceNamespaces = "argo.addons.projectcapsule.dev/managed-source-namespaces"
+
+	// Annotation on managed objects
+	// Project roles which were added by the controller (used to only remove what was added)
+	AnnotationManagedProjectRoles = "argo.addons.projectcapsule.dev/managed-roles"
+
+	// Annotation on managed objects
+	// Origin (namespace/name) of a reflected object
+	AnnotationSource = "argo.addons.projectcapsule.dev/source"
+
+	// Annotation on managed objects
+	// Hash of the inputs the object was last reconciled with, unchanged inputs skip the reconcile of the tenant
+	AnnotationInputHash = "argo.addons.projectcapsule.dev/input-hash"
+
+	// Annotation on managed objects
+	// Prefix for annotations tracking the entries the controller added to a configuration parameter
+	AnnotationManagedParamPrefix = "managed.argo.addons.projectcapsule.dev/"
 )
 
 // Tenant Approject-Name
 func TenantProjectName(tenant *capsulev1beta2.Tenant) (name string) {
+	if group := TenantProjectGroup(tenant); group != "" {
+		return group
+	}
+
 	name = tenant.Annotations[AnnotationProjectName]
 	if name == "" {
 		name = tenant.Name
@@ -45,11 +115,42 @@ func TenantProjectName(tenant *capsulev1beta2.Tenant) (name string) {
 	return
 }
 
+// Project group of the tenant (annotation or label), empty if the tenant has its own appproject
+func TenantProjectGroup(tenant *capsulev1beta2.Tenant) string {
+	if group := tenant.GetAnnotations()[AnnotationProjectGroup]; group != "" {
+		return group
+	}
+
+	return tenant.GetLabels()[AnnotationProjectGroup]
+}
+
 // Tenant ServiceAccount Namespace
 func TenantServiceAccountNamespace(tenant *capsulev1beta2.Tenant) string {
 	return tenant.Annotations[AnnotationServiceAccountNamespace]
 }
 
+// Translators included by the tenant
+func TenantIncludedTranslators(tenant *capsulev1beta2.Tenant) []string {
+	return GetAnnotationList(tenant, AnnotationTranslators)
+}
+
+// Translators excluded by the tenant
+func TenantExcludedTranslators(tenant *capsulev1beta2.Tenant) []string {
+	return GetAnnotationList(tenant, AnnotationExcludeTranslators)
+}
+
+func TenantDeletionPolicy(tenant *capsulev1beta2.Tenant) string {
+	return tenant.GetAnnotations()[AnnotationDeletionPolicy]
+}
+
+func TenantManagedProject(tenant *capsulev1beta2.Tenant) string {
+	return tenant.GetAnnotations()[AnnotationManagedProject]
+}
+
+func TenantRenameApplications(tenant *capsulev1beta2.Tenant) bool {
+	return ProccessBoolean(tenant.GetAnnotations()[AnnotationRenameApplications], false)
+}
+
 func TenantProxyRegister(tenant *capsulev1beta2.Tenant) bool {
 	return ProccessBoolean(tenant.Annotations[AnnotationProxyRegister], true)
 }
@@ -62,6 +163,38 @@ func TenantReadOnly(tenant *capsulev1beta2.Tenant) bool {
 	return ProccessBoolean(tenant.GetAnnotations()[AnnotationProjectReadOnly], false)
 }
 
+func TenantInstance(tenant *capsulev1beta2.Tenant) string {
+	return tenant.GetAnnotations()[AnnotationInstance]
+}
+
+func TenantManagedPolicyTarget(tenant *capsulev1beta2.Tenant) string {
+	return tenant.GetAnnotations()[AnnotationManagedPolicyTarget]
+}
+
+func TenantManagedInstance(tenant *capsulev1beta2.Tenant) string {
+	return tenant.GetAnnotations()[AnnotationManagedInstance]
+}
+
+// Values of the translator overridden on the tenant (YAML)
+func TenantValues(tenant *capsulev1beta2.Tenant, translator string) string {
+	return tenant.GetAnnotations()[AnnotationValuesPrefix+translator]
+}
+
+// ConfigMap (namespace and name) overriding translator values for the tenant, empty if not referenced
+func TenantValuesFrom(tenant *capsulev1beta2.Tenant) (namespace string, name string) {
+	ref := tenant.GetAnnotations()[AnnotationValuesFrom]
+	if ref == "" {
+		return "", ""
+	}
+
+	namespace, name, found := strings.Cut(ref, "/")
+	if !found {
+		return "", ref
+	}
+
+	return namespace, name
+}
+
 func ProccessBoolean(val string, def bool) bool {
 	switch strings.ToLower(val) {
 	case "true", "enable":
@@ -72,3 +205,66 @@ func ProccessBoolean(val string, def bool) bool {
 		return def
 	}
 }
+
+// Tracking annotation for a configuration parameter
+func ManagedParamAnnotation(param string) string {
+	return AnnotationManagedParamPrefix + param
+}
+
+// Tracking annotation for the destinations of remote clusters the tenant added to the appproject
+func ManagedDestinationsAnnotation(tenant string) string {
+	return ManagedParamAnnotation("destinations." + tenant)
+}
+
+// Tracking annotation for the sync window the controller injected for a cordoned tenant
+func ManagedSyncWindowAnnotation(tenant string) string {
+	return ManagedParamAnnotation("syncwindow." + tenant)
+}
+
+// Get a comma separated list from an annotation
+func GetAnnotationList(obj client.Object, key string) (values []string) {
+	raw := obj.GetAnnotations()[key]
+	if raw == "" {
+		return
+	}
+
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return
+}
+
+// Set a comma separated list as annotation, removes the annotation if the list is empty
+func SetAnnotationList(obj client.Object, key string, values []string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	if len(values) == 0 {
+		delete(annotations, key)
+	} else {
+		annotations[key] = strings.Join(values, ",")
+	}
+
+	obj.SetAnnotations(annotations)
+}
+
+// Hash of the inputs the object was last reconciled with
+func InputHash(obj client.Object) string {
+	return obj.GetAnnotations()[AnnotationInputHash]
+}
+
+// Set the hash of the inputs the object is reconciled with
+func SetInputHash(obj client.Object, hash string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[AnnotationInputHash] = hash
+	obj.SetAnnotations(annotations)
+}
@@ -0,0 +1,75 @@
+package meta
+
+import (
+	"testing"
+
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestTenantProjectName(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar-dev"}}
+	assert.Equal(t, "solar-dev", TenantProjectName(tenant))
+	assert.Equal(t, "", TenantProjectGroup(tenant))
+
+	tenant.Annotations = map[string]string{AnnotationProjectName: "renamed"}
+	assert.Equal(t, "renamed", TenantProjectName(tenant))
+
+	tenant.Labels = map[string]string{AnnotationProjectGroup: "solar"}
+	assert.Equal(t, "solar", TenantProjectGroup(tenant))
+	assert.Equal(t, "solar", TenantProjectName(tenant))
+
+	tenant.Annotations[AnnotationProjectGroup] = "energy"
+	assert.Equal(t, "energy", TenantProjectName(tenant))
+}
+
+func TestTenantManagedProject(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar-dev"}}
+	assert.Equal(t, "", TenantManagedProject(tenant))
+	assert.False(t, TenantRenameApplications(tenant))
+
+	tenant.Annotations = map[string]string{
+		AnnotationManagedProject:     "solar-dev",
+		AnnotationRenameApplications: "true",
+	}
+	assert.Equal(t, "solar-dev", TenantManagedProject(tenant))
+	assert.True(t, TenantRenameApplications(tenant))
+}
+
+func TestTenantTranslators(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar-dev"}}
+	assert.Empty(t, TenantIncludedTranslators(tenant))
+	assert.Empty(t, TenantExcludedTranslators(tenant))
+
+	tenant.Annotations = map[string]string{
+		AnnotationTranslators:        "dev, monitoring",
+		AnnotationExcludeTranslators: "prod",
+	}
+	assert.Equal(t, []string{"dev", "monitoring"}, TenantIncludedTranslators(tenant))
+	assert.Equal(t, []string{"prod"}, TenantExcludedTranslators(tenant))
+}
+
+func TestTenantValuesFrom(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar"}}
+	namespace, name := TenantValuesFrom(tenant)
+	assert.Empty(t, namespace)
+	assert.Empty(t, name)
+
+	tenant.Annotations = map[string]string{
+		AnnotationValuesFrom:                  "solar-prod/argo-values",
+		AnnotationValuesPrefix + "monitoring": "retention: 30d",
+	}
+	namespace, name = TenantValuesFrom(tenant)
+	assert.Equal(t, "solar-prod", namespace)
+	assert.Equal(t, "argo-values", name)
+	assert.Equal(t, "retention: 30d", TenantValues(tenant, "monitoring"))
+}
+
+func TestInputHash(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar"}}
+	assert.Empty(t, InputHash(tenant))
+
+	SetInputHash(tenant, "abc")
+	assert.Equal(t, "abc", InputHash(tenant))
+}
@@ -24,6 +24,21 @@ const (
 	// ProgressingReason indicates a condition or event observed progression, for example when the reconciliation of a
 	// resource or an action has started.
 	ProgressingReason string = "Progressing"
+
+	// MatchExpressionCondition indicates if the match expression of a translator compiled
+	MatchExpressionCondition string = "MatchExpression"
+
+	// CompiledReason indicates the expression was compiled successfully
+	CompiledReason string = "Compiled"
+
+	// CompileErrorReason indicates the expression failed to compile
+	CompileErrorReason string = "CompileError"
+
+	// TemplateLibrariesCondition indicates if the template libraries referenced by a translator are valid
+	TemplateLibrariesCondition string = "TemplateLibraries"
+
+	// InvalidReason indicates a referenced object is missing or invalid
+	InvalidReason string = "Invalid"
 )
 
 // Can be used when tenant was successfully translated
@@ -60,3 +75,54 @@ func NewAlreadyExistsCondition(obj client.Object, msg string) metav1.Condition {
 		LastTransitionTime: metav1.Now(),
 	}
 }
+
+func NewProgressingCondition(obj client.Object, msg string) metav1.Condition {
+	return metav1.Condition{
+		Type:               NotReadyCondition,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             ProgressingReason,
+		Message:            msg,
+		LastTransitionTime: metav1.Now(),
+	}
+}
+
+// Compilation result of a match expression
+func NewMatchExpressionCondition(obj client.Object, err error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               MatchExpressionCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             CompiledReason,
+		Message:            "Successfully compiled match expression",
+		LastTransitionTime: metav1.Now(),
+	}
+
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = CompileErrorReason
+		condition.Message = err.Error()
+	}
+
+	return condition
+}
+
+// Validation result of the template libraries
+func NewTemplateLibrariesCondition(obj client.Object, err error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               TemplateLibrariesCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: obj.GetGeneration(),
+		Reason:             SucceededReason,
+		Message:            "Template libraries are valid",
+		LastTransitionTime: metav1.Now(),
+	}
+
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = InvalidReason
+		condition.Message = err.Error()
+	}
+
+	return condition
+}
@@ -0,0 +1,42 @@
+package meta
+
+const (
+	// Default name of the controller settings (--setting-name)
+	DefaultControllerName = "default"
+)
+
+// Name of the controller settings this instance runs with. Finalizers and tracking labels
+// are scoped by it, so multiple controllers can run side by side.
+var controllerName = DefaultControllerName
+
+// Set the name of the controller settings, must be called before the controllers are started
+func SetControllerName(name string) {
+	if name == "" {
+		name = DefaultControllerName
+	}
+
+	controllerName = name
+}
+
+// Name of the controller settings
+func ControllerName() string {
+	return controllerName
+}
+
+// Finalizer of this controller, the default controller uses the unscoped finalizer
+func Finalizer() string {
+	if controllerName == DefaultControllerName {
+		return ControllerFinalizer
+	}
+
+	return ControllerFinalizer + "-" + controllerName
+}
+
+// Managed-by value of this controller, the default controller uses the unscoped value
+func ManagedByValue() string {
+	if controllerName == DefaultControllerName {
+		return ManagedByLabelValue
+	}
+
+	return ManagedByLabelValue + "-" + controllerName
+}
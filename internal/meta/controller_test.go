@@ -0,0 +1,25 @@
+package meta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControllerName(t *testing.T) {
+	defer SetControllerName(DefaultControllerName)
+
+	assert.Equal(t, DefaultControllerName, ControllerName())
+	assert.Equal(t, ControllerFinalizer, Finalizer())
+	assert.Equal(t, ManagedByLabelValue, ManagedByValue())
+	assert.Equal(t, ManagedByLabelValue, TrackingLabels()[ManagedByLabel])
+
+	SetControllerName("blue")
+	assert.Equal(t, "blue", ControllerName())
+	assert.Equal(t, "argo.addons.projectcapsule.dev/finalize-blue", Finalizer())
+	assert.Equal(t, "capsule-argocd-addon-blue", ManagedByValue())
+	assert.Equal(t, "capsule-argocd-addon-blue", TrackingLabels()[ManagedByLabel])
+
+	SetControllerName("")
+	assert.Equal(t, ControllerFinalizer, Finalizer())
+}
@@ -10,12 +10,30 @@ import (
 const (
 	// FinalizerName is the finalizer name for the translator
 	TranslatorFinalizerPrefix = "translator.addons.projectcapsule.dev/"
+
+	// Finalizer for tenants contributing to a shared appproject
+	ProjectMemberFinalizerPrefix = "tenant.addons.projectcapsule.dev/"
 )
 
 func TranslatorFinalizer(name string) string {
 	return TranslatorFinalizerPrefix + name
 }
 
+func ProjectMemberFinalizer(tenant string) string {
+	return ProjectMemberFinalizerPrefix + tenant
+}
+
+// Get all tenants contributing to a shared appproject based on their finalizer
+func GetProjectMemberFinalizers(obj client.Object) (tenants []string) {
+	for _, finalizer := range obj.GetFinalizers() {
+		if strings.HasPrefix(finalizer, ProjectMemberFinalizerPrefix) {
+			tenants = append(tenants, strings.TrimPrefix(finalizer, ProjectMemberFinalizerPrefix))
+		}
+	}
+
+	return
+}
+
 // Get all translators based on their finalizer
 func GetTranslatingFinalizers(obj client.Object) (translators []string) {
 	// Iterate over the finalizers and check if any contain the specified prefix
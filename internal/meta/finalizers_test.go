@@ -78,3 +78,17 @@ func TestContainsTranslatorFinalizerNegative(t *testing.T) {
 	translators := ContainsTranslatorFinalizer(obj)
 	assert.Equal(t, false, translators, "Expected translators to not match")
 }
+
+func TestGetProjectMemberFinalizers(t *testing.T) {
+	obj := &mockObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Finalizers: []string{
+				TranslatorFinalizer("solar"),
+				ProjectMemberFinalizer("solar-dev"),
+				ProjectMemberFinalizer("solar-prod"),
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"solar-dev", "solar-prod"}, GetProjectMemberFinalizers(obj))
+}
@@ -9,6 +9,24 @@ const (
 	// ManagerLabel
 	ManagedTenantLabel = "argo.addons.projectcapsule.dev/tenant"
 
+	// Translator which provisioned the object
+	ManagedTranslatorLabel = "argo.addons.projectcapsule.dev/translator"
+
+	// Label on Secrets in tenant namespaces which are reflected as repository (value is the argo secret-type)
+	RepositoryLabel = "argo.addons.projectcapsule.dev/repository"
+
+	// Local account for which the Secret holds the API token
+	ManagedAccountLabel = "argo.addons.projectcapsule.dev/account"
+
+	// Project role for which the Secret holds the JWT token
+	ManagedProjectRoleLabel = "argo.addons.projectcapsule.dev/project-role"
+
+	// Project variant for which the AppProject was provisioned
+	ManagedProjectVariantLabel = "argo.addons.projectcapsule.dev/project-variant"
+
+	// Remote cluster for which the cluster Secret was provisioned
+	ManagedClusterLabel = "argo.addons.projectcapsule.dev/cluster"
+
 	// ManagedByLabel
 	ManagedByLabel      = "app.kubernetes.io/managed-by"
 	ManagedByLabelValue = "capsule-argocd-addon"
@@ -18,7 +36,7 @@ const (
 // Tracking Labels for resources provisioned by this controller
 func TranslatorTrackingLabels(tenant *capsulev1beta2.Tenant) map[string]string {
 	labels := TrackingLabels()
-	labels[ProvisionedByLabel] = ManagedByLabelValue
+	labels[ProvisionedByLabel] = ManagedByValue()
 	labels[ManagedTenantLabel] = tenant.Name
 
 	return labels
@@ -40,6 +58,7 @@ func WithTranslatorTrackingLabels(obj client.Object, tenant *capsulev1beta2.Tena
 
 func TranslatorRemoveTenantLabels(labels map[string]string) map[string]string {
 	delete(labels, ManagedTenantLabel)
+	delete(labels, ManagedTranslatorLabel)
 	delete(labels, ManagedByLabel)
 
 	return labels
@@ -48,7 +67,7 @@ func TranslatorRemoveTenantLabels(labels map[string]string) map[string]string {
 // Common Labels for tracking resources provisioned by this controller
 func TrackingLabels() map[string]string {
 	return map[string]string{
-		ManagedByLabel: ManagedByLabelValue,
+		ManagedByLabel: ManagedByValue(),
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -45,6 +46,30 @@ func AddDynamicTenantOwnerReference(
 	return nil
 }
 
+// Add an OwnerReference for objects shared by multiple tenants. The object is only garbage collected,
+// when all owning tenants are deleted.
+func AddSharedTenantOwnerReference(
+	schema *runtime.Scheme,
+	obj client.Object,
+	tenant *capsulev1beta2.Tenant,
+) error {
+	return controllerutil.SetOwnerReference(tenant, obj, schema)
+}
+
+// Remove the OwnerReferences of tenants by name
+func RemoveTenantOwnerReferencesByName(obj client.Object, names ...string) {
+	ownerRefs := []metav1.OwnerReference{}
+	for _, ownerRef := range obj.GetOwnerReferences() {
+		if ownerRef.Kind == "Tenant" && StringSliceContains(names, ownerRef.Name) {
+			continue
+		}
+
+		ownerRefs = append(ownerRefs, ownerRef)
+	}
+
+	obj.SetOwnerReferences(ownerRefs)
+}
+
 // Remove an OwnerReference from an object from a tenant
 func RemoveDynamicTenantOwnerReference(obj client.Object, tenant *capsulev1beta2.Tenant) (err error) {
 	ownerRefs := obj.GetOwnerReferences()
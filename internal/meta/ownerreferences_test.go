@@ -101,3 +101,33 @@ func TestHasTenantOwnerReference(t *testing.T) {
 
 	assert.True(t, HasTenantOwnerReference(obj, tenant), "Expected tenant owner reference to be present")
 }
+
+func TestAddSharedTenantOwnerReference(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = capsulev1beta2.AddToScheme(scheme)
+	_ = corev1.AddToScheme(scheme)
+
+	solar := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar", UID: types.UID("1234")}}
+	wind := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "wind", UID: types.UID("5678")}}
+
+	obj := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-config",
+			Namespace: "default",
+		},
+	}
+
+	// A previous controller reference is replaced by a shared reference
+	assert.NoError(t, AddDynamicTenantOwnerReference(context.TODO(), scheme, obj, solar))
+	assert.NoError(t, AddSharedTenantOwnerReference(scheme, obj, solar))
+	assert.NoError(t, AddSharedTenantOwnerReference(scheme, obj, wind))
+
+	assert.Len(t, obj.OwnerReferences, 2)
+	for _, ownerRef := range obj.OwnerReferences {
+		assert.Nil(t, ownerRef.Controller, "Expected shared owner reference to not be controller")
+	}
+
+	RemoveTenantOwnerReferencesByName(obj, "solar")
+	assert.False(t, HasTenantOwnerReference(obj, solar), "Expected tenant owner reference to be removed")
+	assert.True(t, HasTenantOwnerReference(obj, wind), "Expected tenant owner reference to be present")
+}
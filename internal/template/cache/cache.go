@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Compiled templates and rendered outputs are shared between controllers
+var shared = NewCache()
+
+// Functions which make the output depend on more than the inputs of the rendering. Templates using them are
+// rendered on every call. tpl is included, as the rendered text may use any of the others
+var impure = regexp.MustCompile(`\b(` + strings.Join(append([]string{"lookup", "tpl"}, sandbox.Restricted...), "|") + `)\b`)
+
+// Identifies a template of an object (eg. the project settings of a translator)
+type Key struct {
+	UID        types.UID
+	Generation int64
+	Name       string
+}
+
+// Caches the compiled templates of objects per generation and their rendered outputs per scope (eg. a tenant)
+type Cache struct {
+	sync.RWMutex
+	templates map[types.UID]map[string]compiled
+	outputs   map[types.UID]map[string]output
+}
+
+type compiled struct {
+	generation int64
+	text       string
+	functions  string
+	template   *template.Template
+	err        error
+}
+
+type output struct {
+	generation int64
+	hash       string
+	value      interface{}
+}
+
+// Creates an empty cache
+func NewCache() *Cache {
+	return &Cache{
+		templates: make(map[types.UID]map[string]compiled),
+		outputs:   make(map[types.UID]map[string]output),
+	}
+}
+
+// Returns the compiled template bound to the function map. It's only parsed again when the generation, the text
+// or the available functions change
+func (c *Cache) Parse(key Key, text string, funcMap template.FuncMap) (*template.Template, error) {
+	// Objects without UID (not persisted) are not cached
+	if key.UID == "" {
+		return template.New(key.Name).Funcs(funcMap).Parse(text)
+	}
+
+	functions := functionNames(funcMap)
+
+	c.RLock()
+	cached, ok := c.templates[key.UID][key.Name]
+	c.RUnlock()
+
+	if !ok || cached.generation != key.Generation || cached.text != text || cached.functions != functions {
+		tmpl, err := template.New(key.Name).Funcs(funcMap).Parse(text)
+		cached = compiled{
+			generation: key.Generation,
+			text:       text,
+			functions:  functions,
+			template:   tmpl,
+			err:        err,
+		}
+
+		c.Lock()
+		if c.templates[key.UID] == nil {
+			c.templates[key.UID] = make(map[string]compiled)
+		}
+		c.templates[key.UID][key.Name] = cached
+		c.Unlock()
+	}
+
+	if cached.err != nil {
+		return nil, cached.err
+	}
+
+	// The cached template is never executed, each rendering binds its own functions (eg. lookup) to a clone
+	tmpl, err := cached.template.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	return tmpl.Funcs(funcMap), nil
+}
+
+// Returns the output for the inputs of the scope. render is only called when the generation or the inputs changed
+// since the last call, failed renderings are not kept. The output is shared, callers must not modify it
+func (c *Cache) Render(key Key, scope types.UID, inputs interface{}, render func() (interface{}, error)) (interface{}, error) {
+	if key.UID == "" || scope == "" {
+		return render()
+	}
+
+	hash, err := hashInputs(inputs)
+	if err != nil {
+		return render()
+	}
+
+	id := string(scope) + "/" + key.Name
+
+	c.RLock()
+	cached, ok := c.outputs[key.UID][id]
+	c.RUnlock()
+
+	if ok && cached.generation == key.Generation && cached.hash == hash {
+		return cached.value, nil
+	}
+
+	value, err := render()
+	if err != nil {
+		return nil, err
+	}
+
+	c.Lock()
+	if c.outputs[key.UID] == nil {
+		c.outputs[key.UID] = make(map[string]output)
+	}
+	c.outputs[key.UID][id] = output{
+		generation: key.Generation,
+		hash:       hash,
+		value:      value,
+	}
+	c.Unlock()
+
+	return value, nil
+}
+
+// Removes the compiled templates and outputs of the object
+func (c *Cache) Forget(uid types.UID) {
+	c.Lock()
+	delete(c.templates, uid)
+	delete(c.outputs, uid)
+	c.Unlock()
+}
+
+// Removes the outputs of the scope of all objects
+func (c *Cache) ForgetScope(scope types.UID) {
+	prefix := string(scope) + "/"
+
+	c.Lock()
+	defer c.Unlock()
+
+	for _, outputs := range c.outputs {
+		for id := range outputs {
+			if strings.HasPrefix(id, prefix) {
+				delete(outputs, id)
+			}
+		}
+	}
+}
+
+// Compiled template from the shared cache
+func Parse(key Key, text string, funcMap template.FuncMap) (*template.Template, error) {
+	return shared.Parse(key, text, funcMap)
+}
+
+// Output from the shared cache
+func Render(key Key, scope types.UID, inputs interface{}, render func() (interface{}, error)) (interface{}, error) {
+	return shared.Render(key, scope, inputs, render)
+}
+
+// Removes the object from the shared cache
+func Forget(uid types.UID) {
+	shared.Forget(uid)
+}
+
+// Removes the outputs of the scope from the shared cache
+func ForgetScope(scope types.UID) {
+	shared.ForgetScope(scope)
+}
+
+// Returns true if the output of the texts only depends on the inputs of the rendering
+func Pure(texts ...string) bool {
+	for _, text := range texts {
+		if impure.MatchString(text) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hashInputs(inputs interface{}) (string, error) {
+	data, err := json.Marshal(inputs)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func functionNames(funcMap template.FuncMap) string {
+	names := make([]string, 0, len(funcMap))
+	for name := range funcMap {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return strings.Join(names, ",")
+}
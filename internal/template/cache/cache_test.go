@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	c := NewCache()
+	key := Key{UID: "translator", Generation: 1, Name: "settings"}
+
+	execute := func(funcMap template.FuncMap) string {
+		tmpl, err := c.Parse(key, `{{ greet }}`, funcMap)
+		assert.NoError(t, err)
+
+		var buf bytes.Buffer
+		assert.NoError(t, tmpl.Execute(&buf, nil))
+
+		return buf.String()
+	}
+
+	// Each rendering binds its own functions
+	assert.Equal(t, "hello", execute(template.FuncMap{"greet": func() string { return "hello" }}))
+	assert.Equal(t, "servus", execute(template.FuncMap{"greet": func() string { return "servus" }}))
+	assert.Len(t, c.templates["translator"], 1)
+
+	// Errors are kept until the generation changes
+	_, err := c.Parse(Key{UID: "translator", Generation: 2, Name: "settings"}, `{{ greet`, template.FuncMap{})
+	assert.Error(t, err)
+
+	c.Forget("translator")
+	assert.Empty(t, c.templates)
+}
+
+func TestRender(t *testing.T) {
+	c := NewCache()
+	key := Key{UID: "translator", Generation: 1, Name: "settings"}
+
+	calls := 0
+	render := func() (interface{}, error) {
+		calls++
+
+		return calls, nil
+	}
+
+	// Identical inputs are rendered once
+	out, err := c.Render(key, "solar", map[string]string{"tenant": "solar"}, render)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, out)
+
+	out, _ = c.Render(key, "solar", map[string]string{"tenant": "solar"}, render)
+	assert.Equal(t, 1, out)
+
+	// Changed inputs, scope or generation are rendered again
+	out, _ = c.Render(key, "solar", map[string]string{"tenant": "solar", "env": "prod"}, render)
+	assert.Equal(t, 2, out)
+
+	out, _ = c.Render(key, "oil", map[string]string{"tenant": "solar", "env": "prod"}, render)
+	assert.Equal(t, 3, out)
+
+	key.Generation = 2
+	out, _ = c.Render(key, "oil", map[string]string{"tenant": "solar", "env": "prod"}, render)
+	assert.Equal(t, 4, out)
+
+	// Failed renderings are not kept
+	_, err = c.Render(Key{UID: "translator", Generation: 2, Name: "policy"}, "solar", nil, func() (interface{}, error) {
+		return nil, errors.New("failed")
+	})
+	assert.Error(t, err)
+	assert.NotContains(t, c.outputs["translator"], "solar/policy")
+
+	// Objects without UID are always rendered
+	out, _ = c.Render(Key{Name: "settings"}, "solar", nil, render)
+	assert.Equal(t, 5, out)
+	out, _ = c.Render(Key{Name: "settings"}, "solar", nil, render)
+	assert.Equal(t, 6, out)
+
+	c.ForgetScope("oil")
+	assert.Len(t, c.outputs["translator"], 1)
+}
+
+func TestPure(t *testing.T) {
+	assert.True(t, Pure(`{{ .Tenant.Name | upper }}`, `{{ include "labels" . }}`))
+	assert.True(t, Pure(`{{ .Values.lookupTable }}`))
+	assert.False(t, Pure(`{{ (lookup "v1" "ConfigMap" "platform" "repositories").data }}`))
+	assert.False(t, Pure(`{{ tpl .Values.template . }}`))
+	assert.False(t, Pure(`{{ now | date "2006" }}`))
+}
@@ -1,22 +1,158 @@
 package template
 
 import (
+	"context"
+	"sort"
+
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
 	"github.com/peak-scale/capsule-argo-addon/internal/utils"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func ConfigContext(cluster string, translator *v1alpha1.ArgoTranslator, config *v1alpha1.ArgoAddonSpec, tenant *capsulev1beta2.Tenant) interface{} {
+// Objects from the cluster exposed in the context
+type ContextObjects struct {
+	// Namespaces of the tenant
+	Namespaces []corev1.Namespace
+
+	// AppProject of the tenant, nil if it does not exist (yet)
+	AppProject *argocdv1alpha1.AppProject
+}
+
+// Loads the namespaces and the appproject of the tenant. Namespaces which no longer exist are skipped
+func TenantObjects(
+	ctx context.Context,
+	c client.Reader,
+	config *v1alpha1.ArgoAddonSpec,
+	tenant *capsulev1beta2.Tenant,
+) (*ContextObjects, error) {
+	objects := &ContextObjects{}
+
+	for _, name := range tenant.Status.Namespaces {
+		namespace := corev1.Namespace{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, &namespace); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+
+			return nil, err
+		}
+
+		objects.Namespaces = append(objects.Namespaces, namespace)
+	}
+
+	project := &argocdv1alpha1.AppProject{}
+	err := c.Get(ctx, client.ObjectKey{Name: meta.TenantProjectName(tenant), Namespace: config.Argo.Namespace}, project)
+	switch {
+	case err == nil:
+		objects.AppProject = project
+	case !k8serrors.IsNotFound(err):
+		return nil, err
+	}
+
+	return objects, nil
+}
+
+// Context for the templates of a translator. The objects are optional, their keys are empty without them
+func ConfigContext(
+	cluster string,
+	translator *v1alpha1.ArgoTranslator,
+	config *v1alpha1.ArgoAddonSpec,
+	tenant *capsulev1beta2.Tenant,
+	values map[string]interface{},
+	objects *ContextObjects,
+) interface{} {
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	if objects == nil {
+		objects = &ContextObjects{}
+	}
+
+	namespace, name := config.TenantServiceAccount(tenant)
 
 	ctx := map[string]interface{}{
-		"Tenant": map[string]interface{}{
-			"Name":       tenant.Name,
-			"Namespaces": tenant.Status.Namespaces,
-			"Object":     utils.Mapify(tenant),
+		"Tenant":     tenantContext(tenant),
+		"Translator": translatorContext(translator),
+		"Namespaces": namespacesContext(objects.Namespaces),
+		"Project": map[string]interface{}{
+			"Name":  meta.TenantProjectName(tenant),
+			"Group": meta.TenantProjectGroup(tenant),
+		},
+		"AppProject": appProjectContext(objects.AppProject),
+		"ServiceAccount": map[string]interface{}{
+			"Name":      name,
+			"Namespace": namespace,
+			"Username":  "system:serviceaccount:" + namespace + ":" + name,
 		},
 		"Config":   utils.Mapify(config),
 		"Endpoint": cluster,
+		"Values":   values,
 	}
 
 	return ctx
 }
+
+// The tenant in its JSON shape (eg. .Tenant.spec.owners). The keys Name, Namespaces and Object are
+// kept for existing templates, Object (Go field names) is deprecated
+func tenantContext(tenant *capsulev1beta2.Tenant) map[string]interface{} {
+	ctx := toUnstructured(tenant)
+	ctx["Name"] = tenant.Name
+	ctx["Namespaces"] = tenant.Status.Namespaces
+	ctx["Object"] = utils.Mapify(tenant)
+
+	return ctx
+}
+
+func translatorContext(translator *v1alpha1.ArgoTranslator) map[string]interface{} {
+	if translator == nil {
+		return map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"Name":        translator.Name,
+		"Labels":      translator.GetLabels(),
+		"Annotations": translator.GetAnnotations(),
+	}
+}
+
+func namespacesContext(namespaces []corev1.Namespace) []interface{} {
+	sorted := append([]corev1.Namespace{}, namespaces...)
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a].Name < sorted[b].Name
+	})
+
+	ctx := make([]interface{}, 0, len(sorted))
+	for _, namespace := range sorted {
+		ctx = append(ctx, map[string]interface{}{
+			"Name":   namespace.Name,
+			"Labels": namespace.GetLabels(),
+		})
+	}
+
+	return ctx
+}
+
+func appProjectContext(project *argocdv1alpha1.AppProject) map[string]interface{} {
+	if project == nil {
+		return map[string]interface{}{}
+	}
+
+	return toUnstructured(project)
+}
+
+// JSON shape of the object
+func toUnstructured(obj runtime.Object) map[string]interface{} {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return map[string]interface{}{}
+	}
+
+	return content
+}
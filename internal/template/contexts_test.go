@@ -9,9 +9,12 @@ import (
 	"testing"
 	"text/template"
 
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
 	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
 	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -69,7 +72,7 @@ config:
 		Status: capsulev1beta2.TenantStatus{
 			Namespaces: []string{"namespace1", "namespace2"},
 		},
-	})
+	}, nil, nil)
 
 	// Run the template
 	tmpl, err := template.New("test").Funcs(ExtraFuncMap()).Parse(yamlTemplate)
@@ -121,6 +124,53 @@ config:
 	}
 }
 
+// The tenant and the objects are exposed with their JSON field names
+func TestConfigContext(t *testing.T) {
+	tplCtx := ConfigContext("example-cluster", &v1alpha1.ArgoTranslator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "dev",
+			Labels: map[string]string{"team": "platform"},
+		},
+	}, &v1alpha1.ArgoAddonSpec{
+		Proxy: v1alpha1.ControllerCapsuleProxyConfig{ServiceAccountNamespace: "capsule-argo-addon"},
+	}, &capsulev1beta2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "solar",
+			Annotations: map[string]string{"argo.addons.projectcapsule.dev/name": "solar-project"},
+		},
+		Spec: capsulev1beta2.TenantSpec{
+			Owners: []capsulev1beta2.OwnerSpec{{Kind: "User", Name: "alice"}},
+		},
+		Status: capsulev1beta2.TenantStatus{Namespaces: []string{"solar-prod", "solar-dev"}},
+	}, nil, &ContextObjects{
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "solar-prod", Labels: map[string]string{"env": "prod"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "solar-dev", Labels: map[string]string{"env": "dev"}}},
+		},
+		AppProject: &argocdv1alpha1.AppProject{
+			ObjectMeta: metav1.ObjectMeta{Name: "solar-project"},
+			Spec:       argocdv1alpha1.AppProjectSpec{SourceRepos: []string{"https://github.com/solar"}},
+		},
+	})
+
+	tmpl, err := template.New("test").Funcs(ExtraFuncMap()).Parse(
+		`{{ (index .Tenant.spec.owners 0).name }};` +
+			`{{ (index .Tenant.Object.Spec.Owners 0).Name }};` +
+			`{{ .Translator.Name }}/{{ .Translator.Labels.team }};` +
+			`{{ range .Namespaces }}{{ .Name }}={{ .Labels.env }},{{ end }};` +
+			`{{ .Project.Name }};` +
+			`{{ index .AppProject.spec.sourceRepos 0 }};` +
+			`{{ .ServiceAccount.Username }}`)
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, tmpl.Execute(&buf, tplCtx))
+	assert.Equal(t,
+		"alice;alice;dev/platform;solar-dev=dev,solar-prod=prod,;solar-project;https://github.com/solar;"+
+			"system:serviceaccount:capsule-argo-addon:solar",
+		buf.String())
+}
+
 // TestRenderContextToMarkdown renders the template context into a Markdown file
 func TestRenderContextToMarkdown(t *testing.T) {
 	// Load markdown template from file
@@ -168,6 +218,16 @@ func TestRenderContextToMarkdown(t *testing.T) {
 		Status: capsulev1beta2.TenantStatus{
 			Namespaces: []string{"namespace1", "namespace2"},
 		},
+	}, map[string]interface{}{
+		"environment": "prod",
+		"resources": map[string]interface{}{
+			"cpu": "500m",
+		},
+	}, &ContextObjects{
+		Namespaces: []corev1.Namespace{
+			{ObjectMeta: metav1.ObjectMeta{Name: "namespace1", Labels: map[string]string{"env": "prod"}}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "namespace2"}},
+		},
 	})
 
 	// Parse the markdown template
@@ -16,16 +16,20 @@ package template
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"strings"
 	"text/template"
 
 	"github.com/BurntSushi/toml"
 	"github.com/Masterminds/sprig/v3"
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
 	"gopkg.in/yaml.v3"
 )
 
-// TxtFuncMap returns an aggregated template function map. Currently (custom functions + sprig)
-func ExtraFuncMap() template.FuncMap {
+// TxtFuncMap returns an aggregated template function map. Currently (custom functions + sprig). The named
+// templates of the libraries are available with include and tpl.
+func ExtraFuncMap(libraries ...*v1alpha1.ArgoTemplateLibrary) template.FuncMap {
 	funcMap := sprig.FuncMap()
 
 	extraFuncs := template.FuncMap{
@@ -37,13 +41,21 @@ func ExtraFuncMap() template.FuncMap {
 		"toJson":        toJSON,
 		"fromJson":      fromJSON,
 		"fromJsonArray": fromJSONArray,
+		"lookup":        lookupUnavailable,
 	}
 
 	for k, v := range extraFuncs {
 		funcMap[k] = v
 	}
 
-	return funcMap
+	addLibraryFuncs(funcMap, libraries)
+
+	return sandbox.Restrict(funcMap)
+}
+
+// lookupUnavailable is used where no cluster access is available for the template
+func lookupUnavailable(string, string, string, string) (map[string]interface{}, error) {
+	return nil, errors.New("lookup is not available in this context")
 }
 
 // toYAML takes an interface, marshals it to yaml, and returns a string. It will
@@ -0,0 +1,156 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Maximum nesting of include and tpl calls
+const MaxIncludeDepth = 32
+
+// Named templates of the libraries, rendered with include and tpl
+type library struct {
+	libraries []*v1alpha1.ArgoTemplateLibrary
+	funcMap   template.FuncMap
+	root      *template.Template
+	err       error
+	parsed    bool
+	// Chain of the currently rendered includes
+	stack []string
+}
+
+// Adds include and tpl for the libraries to the funcmap
+func addLibraryFuncs(funcMap template.FuncMap, libraries []*v1alpha1.ArgoTemplateLibrary) {
+	l := &library{
+		libraries: libraries,
+		funcMap:   funcMap,
+	}
+
+	funcMap["include"] = l.include
+	funcMap["tpl"] = l.tpl
+}
+
+// Parses the named templates of all libraries once
+func (l *library) parse() (*template.Template, error) {
+	if l.parsed {
+		return l.root, l.err
+	}
+
+	l.parsed = true
+	l.root = template.New("library").Funcs(l.funcMap)
+
+	origins := make(map[string]string)
+	for _, lib := range l.libraries {
+		for _, named := range lib.Spec.Templates {
+			if origin, ok := origins[named.Name]; ok {
+				l.err = fmt.Errorf("template %q is defined in libraries %s and %s", named.Name, origin, lib.Name)
+
+				return nil, l.err
+			}
+
+			origins[named.Name] = lib.Name
+
+			if _, err := l.root.New(named.Name).Parse(named.Template); err != nil {
+				l.err = fmt.Errorf("invalid template %q in library %s: %w", named.Name, lib.Name, err)
+
+				return nil, l.err
+			}
+		}
+	}
+
+	return l.root, nil
+}
+
+// Renders the named template with the given data
+func (l *library) include(name string, data interface{}) (string, error) {
+	for _, included := range l.stack {
+		if included == name {
+			return "", fmt.Errorf("include cycle detected: %s -> %s", strings.Join(l.stack, " -> "), name)
+		}
+	}
+
+	root, err := l.parse()
+	if err != nil {
+		return "", err
+	}
+
+	t := root.Lookup(name)
+	if t == nil {
+		return "", fmt.Errorf("template %q not found in the referenced libraries", name)
+	}
+
+	return l.render(name, t, data)
+}
+
+// Renders the text as template with the given data, the named templates are available
+func (l *library) tpl(text string, data interface{}) (string, error) {
+	root, err := l.parse()
+	if err != nil {
+		return "", err
+	}
+
+	t, err := root.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	t, err = t.New("tpl").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid tpl: %w", err)
+	}
+
+	return l.render("tpl", t, data)
+}
+
+func (l *library) render(name string, t *template.Template, data interface{}) (string, error) {
+	if len(l.stack) >= MaxIncludeDepth {
+		return "", fmt.Errorf("maximum template depth (%d) exceeded: %s -> %s", MaxIncludeDepth, strings.Join(l.stack, " -> "), name)
+	}
+
+	l.stack = append(l.stack, name)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	rendered, err := sandbox.Execute(t, data)
+	if err != nil {
+		return "", err
+	}
+
+	return string(rendered), nil
+}
+
+// Verifies the named templates of the libraries can be parsed and are unique
+func ValidateLibraries(libraries []*v1alpha1.ArgoTemplateLibrary) error {
+	l := &library{
+		libraries: libraries,
+		funcMap:   ExtraFuncMap(),
+	}
+
+	_, err := l.parse()
+
+	return err
+}
+
+// Template libraries referenced by the translator
+func TranslatorLibraries(
+	ctx context.Context,
+	c client.Reader,
+	translator *v1alpha1.ArgoTranslator,
+) ([]*v1alpha1.ArgoTemplateLibrary, error) {
+	libraries := make([]*v1alpha1.ArgoTemplateLibrary, 0, len(translator.Spec.Libraries))
+	for _, name := range translator.Spec.Libraries {
+		lib := &v1alpha1.ArgoTemplateLibrary{}
+		if err := c.Get(ctx, client.ObjectKey{Name: name}, lib); err != nil {
+			return nil, fmt.Errorf("failed to get template library %s: %w", name, err)
+		}
+
+		libraries = append(libraries, lib)
+	}
+
+	return libraries, nil
+}
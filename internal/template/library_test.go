@@ -0,0 +1,97 @@
+package template
+
+import (
+	"bytes"
+	"testing"
+	"text/template"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newLibrary(name string, templates map[string]string) *v1alpha1.ArgoTemplateLibrary {
+	lib := &v1alpha1.ArgoTemplateLibrary{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}
+
+	for n, body := range templates {
+		lib.Spec.Templates = append(lib.Spec.Templates, v1alpha1.ArgoNamedTemplate{Name: n, Template: body})
+	}
+
+	return lib
+}
+
+func render(t *testing.T, text string, data interface{}, libraries ...*v1alpha1.ArgoTemplateLibrary) (string, error) {
+	t.Helper()
+
+	tmpl, err := template.New("test").Funcs(ExtraFuncMap(libraries...)).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+
+	return buf.String(), err
+}
+
+func TestLibraryInclude(t *testing.T) {
+	naming := newLibrary("naming", map[string]string{
+		"project.name": `{{ .Tenant }}-{{ include "project.suffix" . }}`,
+	})
+	common := newLibrary("common", map[string]string{
+		"project.suffix": `{{ .Environment | upper }}`,
+	})
+
+	out, err := render(t, `name: {{ include "project.name" . | quote }}`, map[string]string{
+		"Tenant":      "solar",
+		"Environment": "prod",
+	}, naming, common)
+	assert.NoError(t, err)
+	assert.Equal(t, `name: "solar-PROD"`, out)
+
+	_, err = render(t, `{{ include "missing" . }}`, nil, naming)
+	assert.ErrorContains(t, err, `template "missing" not found`)
+}
+
+func TestLibraryTpl(t *testing.T) {
+	lib := newLibrary("naming", map[string]string{
+		"greeting": `hello {{ . }}`,
+	})
+
+	out, err := render(t, `{{ tpl "{{ include \"greeting\" .Name }}!" . }}`, map[string]string{"Name": "solar"}, lib)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello solar!", out)
+}
+
+func TestLibraryCycle(t *testing.T) {
+	lib := newLibrary("cycle", map[string]string{
+		"a": `{{ include "b" . }}`,
+		"b": `{{ include "a" . }}`,
+	})
+
+	_, err := render(t, `{{ include "a" . }}`, nil, lib)
+	assert.ErrorContains(t, err, "include cycle detected: a -> b -> a")
+}
+
+func TestLibraryDepth(t *testing.T) {
+	_, err := render(t, `{{ tpl . . }}`, `{{ tpl . . }}`)
+	assert.ErrorContains(t, err, "maximum template depth")
+}
+
+func TestValidateLibraries(t *testing.T) {
+	assert.NoError(t, ValidateLibraries([]*v1alpha1.ArgoTemplateLibrary{
+		newLibrary("a", map[string]string{"one": "1"}),
+		newLibrary("b", map[string]string{"two": "2"}),
+	}))
+
+	assert.ErrorContains(t, ValidateLibraries([]*v1alpha1.ArgoTemplateLibrary{
+		newLibrary("a", map[string]string{"one": "1"}),
+		newLibrary("b", map[string]string{"one": "2"}),
+	}), `template "one" is defined in libraries a and b`)
+
+	assert.ErrorContains(t, ValidateLibraries([]*v1alpha1.ArgoTemplateLibrary{
+		newLibrary("a", map[string]string{"broken": "{{ .Name"}),
+	}), `invalid template "broken" in library a`)
+}
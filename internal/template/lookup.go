@@ -0,0 +1,102 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Called for every object (name empty for lists) read with lookup
+type LookupTracker func(gvk schema.GroupVersionKind, namespace string, name string)
+
+// Reads cluster objects for templates (similar to the helm lookup function), restricted to the resources and
+// namespaces allowed in the configuration
+type Lookup struct {
+	ctx      context.Context
+	reader   client.Reader
+	mapper   apimeta.RESTMapper
+	settings *v1alpha1.ArgoAddonSpec
+	track    LookupTracker
+}
+
+func NewLookup(
+	ctx context.Context,
+	reader client.Reader,
+	mapper apimeta.RESTMapper,
+	settings *v1alpha1.ArgoAddonSpec,
+	track LookupTracker,
+) *Lookup {
+	return &Lookup{
+		ctx:      ctx,
+		reader:   reader,
+		mapper:   mapper,
+		settings: settings,
+		track:    track,
+	}
+}
+
+// Adds lookup to the funcmap
+func (l *Lookup) AddTo(funcMap template.FuncMap) template.FuncMap {
+	funcMap["lookup"] = l.Lookup
+
+	return funcMap
+}
+
+// Returns the object (or a list with "items", when the name is empty). Returns an empty map, if the object does
+// not exist
+func (l *Lookup) Lookup(apiVersion string, kind string, namespace string, name string) (map[string]interface{}, error) {
+	if !l.settings.LookupAllowed(apiVersion, kind, namespace) {
+		return nil, fmt.Errorf("lookup of %s %s in namespace %q is not allowed", apiVersion, kind, namespace)
+	}
+
+	gvk := schema.FromAPIVersionAndKind(apiVersion, kind)
+
+	mapping, err := l.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("lookup of %s %s: %w", apiVersion, kind, err)
+	}
+
+	namespaced := mapping.Scope.Name() == apimeta.RESTScopeNameNamespace
+	switch {
+	case namespaced && namespace == "":
+		return nil, fmt.Errorf("lookup of %s %s requires a namespace", apiVersion, kind)
+	case !namespaced:
+		namespace = ""
+	}
+
+	// Track before reading, so the creation of the object is noticed as well
+	if l.track != nil {
+		l.track(gvk, namespace, name)
+	}
+
+	if name == "" {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(kind + "List"))
+
+		if err := l.reader.List(l.ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, err
+		}
+
+		return list.UnstructuredContent(), nil
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+
+	if err := l.reader.Get(l.ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return map[string]interface{}{}, nil
+		}
+
+		return nil, err
+	}
+
+	return obj.UnstructuredContent(), nil
+}
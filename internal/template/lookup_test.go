@@ -0,0 +1,119 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reader serving unstructured objects from memory
+type objectReader struct {
+	objects []*unstructured.Unstructured
+}
+
+func (r *objectReader) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	u := obj.(*unstructured.Unstructured)
+	for _, o := range r.objects {
+		if o.GroupVersionKind() == u.GroupVersionKind() && o.GetNamespace() == key.Namespace && o.GetName() == key.Name {
+			o.DeepCopyInto(u)
+
+			return nil
+		}
+	}
+
+	return k8serrors.NewNotFound(schema.GroupResource{Resource: u.GetKind()}, key.Name)
+}
+
+func (r *objectReader) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	options := &client.ListOptions{}
+	options.ApplyOptions(opts)
+
+	l := list.(*unstructured.UnstructuredList)
+	for _, o := range r.objects {
+		if o.GetKind()+"List" == l.GetKind() && o.GetNamespace() == options.Namespace {
+			l.Items = append(l.Items, *o.DeepCopy())
+		}
+	}
+
+	return nil
+}
+
+func TestLookup(t *testing.T) {
+	configMap := &unstructured.Unstructured{}
+	configMap.SetAPIVersion("v1")
+	configMap.SetKind("ConfigMap")
+	configMap.SetNamespace("platform")
+	configMap.SetName("repositories")
+	_ = unstructured.SetNestedField(configMap.Object, "https://github.com/solar/app", "data", "repo")
+
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName("solar-prod")
+	namespace.SetLabels(map[string]string{"env": "prod"})
+
+	mapper := apimeta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}, apimeta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Namespace"}, apimeta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, apimeta.RESTScopeNamespace)
+
+	settings := &v1alpha1.ArgoAddonSpec{
+		Lookup: v1alpha1.ControllerLookupConfig{
+			Resources: []v1alpha1.ControllerLookupResource{
+				{APIVersion: "v1", Kind: "ConfigMap"},
+				{APIVersion: "v1", Kind: "Namespace"},
+			},
+			Namespaces: []string{"platform"},
+		},
+	}
+
+	var tracked []string
+	lookup := NewLookup(context.Background(), &objectReader{objects: []*unstructured.Unstructured{configMap, namespace}}, mapper, settings,
+		func(gvk schema.GroupVersionKind, namespace string, name string) {
+			tracked = append(tracked, gvk.Kind+"/"+namespace+"/"+name)
+		})
+
+	obj, err := lookup.Lookup("v1", "ConfigMap", "platform", "repositories")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://github.com/solar/app", obj["data"].(map[string]interface{})["repo"])
+
+	obj, err = lookup.Lookup("v1", "ConfigMap", "platform", "absent")
+	assert.NoError(t, err)
+	assert.Empty(t, obj, "absent objects are empty")
+
+	obj, err = lookup.Lookup("v1", "ConfigMap", "platform", "")
+	assert.NoError(t, err)
+	assert.Len(t, obj["items"], 1)
+
+	obj, err = lookup.Lookup("v1", "Namespace", "", "solar-prod")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"env": "prod"}, obj["metadata"].(map[string]interface{})["labels"])
+
+	_, err = lookup.Lookup("v1", "Secret", "platform", "credentials")
+	assert.ErrorContains(t, err, "not allowed", "resources must be allowed")
+
+	_, err = lookup.Lookup("v1", "ConfigMap", "kube-system", "repositories")
+	assert.ErrorContains(t, err, "not allowed", "namespaces must be allowed")
+
+	_, err = lookup.Lookup("v1", "ConfigMap", "", "")
+	assert.ErrorContains(t, err, "requires a namespace")
+
+	assert.Equal(t, []string{
+		"ConfigMap/platform/repositories",
+		"ConfigMap/platform/absent",
+		"ConfigMap/platform/",
+		"Namespace//solar-prod",
+	}, tracked)
+}
+
+func TestLookupUnavailable(t *testing.T) {
+	_, err := render(t, `{{ lookup "v1" "ConfigMap" "platform" "repositories" }}`, nil)
+	assert.ErrorContains(t, err, "lookup is not available")
+}
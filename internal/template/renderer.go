@@ -0,0 +1,212 @@
+package template
+
+import (
+	"fmt"
+	"text/template"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/cache"
+	"github.com/peak-scale/capsule-argo-addon/internal/template/sandbox"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Renders the templates of a translator for a tenant. The templates are compiled once per generation of the
+// translator and the outputs are kept per tenant, identical inputs return the previous output without rendering
+type Renderer struct {
+	// Context the templates are rendered with
+	Context interface{}
+
+	// Functions available to the templates
+	FuncMap template.FuncMap
+
+	translator *v1alpha1.ArgoTranslator
+	tenant     *capsulev1beta2.Tenant
+	libraries  []*v1alpha1.ArgoTemplateLibrary
+}
+
+// Inputs of the outputs, besides the generation of the translator
+type rendererInputs struct {
+	Context   interface{}
+	Libraries []libraryInput
+}
+
+type libraryInput struct {
+	Name       string
+	Generation int64
+}
+
+// Creates a renderer for the translator and the tenant
+func NewRenderer(
+	translator *v1alpha1.ArgoTranslator,
+	tenant *capsulev1beta2.Tenant,
+	libraries []*v1alpha1.ArgoTemplateLibrary,
+	context interface{},
+	funcMap template.FuncMap,
+) *Renderer {
+	return &Renderer{
+		Context:    context,
+		FuncMap:    funcMap,
+		translator: translator,
+		tenant:     tenant,
+		libraries:  libraries,
+	}
+}
+
+// Combined configuration of the appproject of the tenant
+func (r *Renderer) ProjectConfig() (v1alpha1.ArgocdProjectStructuredProperties, error) {
+	return r.projectConfig("settings", &r.translator.Spec.ProjectSettings)
+}
+
+// Combined configuration of the appproject of the project variant
+func (r *Renderer) VariantConfig(variant *v1alpha1.ArgocdProjectVariant) (v1alpha1.ArgocdProjectStructuredProperties, error) {
+	return r.projectConfig("projects/"+variant.Name, &variant.ProjectSettings)
+}
+
+// Rendered Application or ApplicationSet
+func (r *Renderer) Application(application *v1alpha1.ArgocdApplicationTemplate) (client.Object, error) {
+	out, err := r.render("applications/"+application.Name, application.Template, func(tmpl *template.Template) (interface{}, error) {
+		return application.RenderFrom(tmpl, r.Context)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return out.(client.Object).DeepCopyObject().(client.Object), nil
+}
+
+// Rendered custom policy
+func (r *Renderer) CustomPolicy() (string, error) {
+	out, err := r.render("customPolicy", r.translator.Spec.CustomPolicy, func(tmpl *template.Template) (interface{}, error) {
+		rendered, err := sandbox.Execute(tmpl, r.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(rendered), nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return out.(string), nil
+}
+
+func (r *Renderer) projectConfig(
+	name string,
+	properties *v1alpha1.ArgocdProjectProperties,
+) (v1alpha1.ArgocdProjectStructuredProperties, error) {
+	out, err := r.render(name, properties.Template, func(tmpl *template.Template) (interface{}, error) {
+		return properties.GetConfigFrom(tmpl, r.Context)
+	})
+	if err != nil {
+		return v1alpha1.ArgocdProjectStructuredProperties{}, err
+	}
+
+	props := out.(v1alpha1.ArgocdProjectStructuredProperties)
+
+	return *props.DeepCopy(), nil
+}
+
+// Renders the named template of the translator, the output is only kept when it solely depends on the inputs
+func (r *Renderer) render(
+	name string,
+	text string,
+	execute func(*template.Template) (interface{}, error),
+) (interface{}, error) {
+	key := cache.Key{UID: r.translator.UID, Generation: r.translator.Generation, Name: name}
+
+	render := func() (interface{}, error) {
+		tmpl, err := cache.Parse(key, text, r.FuncMap)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template: %w", err)
+		}
+
+		return execute(tmpl)
+	}
+
+	if !r.pure(text) {
+		return render()
+	}
+
+	return cache.Render(key, r.tenant.UID, r.inputs(), render)
+}
+
+func (r *Renderer) pure(text string) bool {
+	return cache.Pure(append(libraryTexts(r.libraries), text)...)
+}
+
+func (r *Renderer) inputs() rendererInputs {
+	inputs := rendererInputs{Context: stableContext(r.Context)}
+	for _, library := range r.libraries {
+		inputs.Libraries = append(inputs.Libraries, libraryInput{Name: library.Name, Generation: library.Generation})
+	}
+
+	return inputs
+}
+
+// Context without the parts of the tenant and the appproject which change on every update or with their status
+// (the namespaces of the tenant are kept with .Tenant.Namespaces). They would change the inputs without changing
+// the outputs
+func stableContext(context interface{}) interface{} {
+	ctx, ok := context.(map[string]interface{})
+	if !ok {
+		return context
+	}
+
+	stable := make(map[string]interface{}, len(ctx))
+	for key, value := range ctx {
+		if object, ok := value.(map[string]interface{}); ok && (key == "Tenant" || key == "AppProject") {
+			value = withoutVolatileFields(object)
+		}
+
+		stable[key] = value
+	}
+
+	return stable
+}
+
+// Copy of the object (JSON or Go field names) without status, resourceVersion and managedFields
+func withoutVolatileFields(object map[string]interface{}) map[string]interface{} {
+	stripped := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		switch key {
+		case "status", "Status", "resourceVersion", "ResourceVersion", "managedFields", "ManagedFields":
+			continue
+		case "metadata", "ObjectMeta", "Object":
+			if nested, ok := value.(map[string]interface{}); ok {
+				value = withoutVolatileFields(nested)
+			}
+		}
+
+		stripped[key] = value
+	}
+
+	return stripped
+}
+
+// Returns true if the outputs of all templates of the translator only depend on their inputs, templates using
+// lookup, tpl or restricted functions may render differently for the same inputs
+func TranslatorPure(translator *v1alpha1.ArgoTranslator, libraries []*v1alpha1.ArgoTemplateLibrary) bool {
+	texts := append(libraryTexts(libraries), translator.Spec.ProjectSettings.Template, translator.Spec.CustomPolicy)
+	for _, application := range translator.Spec.Applications {
+		texts = append(texts, application.Template)
+	}
+
+	for _, variant := range translator.Spec.Projects {
+		texts = append(texts, variant.ProjectSettings.Template)
+	}
+
+	return cache.Pure(texts...)
+}
+
+func libraryTexts(libraries []*v1alpha1.ArgoTemplateLibrary) []string {
+	var texts []string
+	for _, library := range libraries {
+		for _, named := range library.Spec.Templates {
+			texts = append(texts, named.Template)
+		}
+	}
+
+	return texts
+}
@@ -0,0 +1,98 @@
+package template
+
+import (
+	"testing"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRendererProjectConfig(t *testing.T) {
+	translator := &v1alpha1.ArgoTranslator{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", UID: "renderer-translator", Generation: 1},
+		Spec: v1alpha1.ArgoTranslatorSpec{
+			ProjectSettings: v1alpha1.ArgocdProjectProperties{
+				Template: `
+spec:
+  sourceRepos:
+    - "https://github.com/{{ .Tenant.Name }}"`,
+			},
+		},
+	}
+	tenant := &capsulev1beta2.Tenant{ObjectMeta: metav1.ObjectMeta{Name: "solar", UID: "renderer-tenant"}}
+	config := &v1alpha1.ArgoAddonSpec{}
+
+	renderer := NewRenderer(translator, tenant, nil, ConfigContext("", translator, config, tenant, nil, nil), ExtraFuncMap())
+
+	cfg, err := renderer.ProjectConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/solar"}, cfg.ProjectSpec.SourceRepos)
+
+	// Memoized outputs are copies
+	cfg.ProjectSpec.SourceRepos[0] = "changed"
+	cfg, err = renderer.ProjectConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://github.com/solar"}, cfg.ProjectSpec.SourceRepos)
+
+	// Parse errors are reported
+	broken := translator.DeepCopy()
+	broken.Generation = 2
+	broken.Spec.ProjectSettings.Template = `{{ .Tenant.Name`
+	_, err = NewRenderer(broken, tenant, nil, ConfigContext("", broken, config, tenant, nil, nil), ExtraFuncMap()).ProjectConfig()
+	assert.ErrorContains(t, err, "error parsing template")
+}
+
+func TestRendererMemoization(t *testing.T) {
+	translator := &v1alpha1.ArgoTranslator{
+		ObjectMeta: metav1.ObjectMeta{Name: "dev", UID: "memo-translator", Generation: 1},
+		Spec: v1alpha1.ArgoTranslatorSpec{
+			ProjectSettings: v1alpha1.ArgocdProjectProperties{
+				Template: `
+spec:
+  description: "{{ renders }} {{ .Tenant.metadata.labels.stage }}"`,
+			},
+		},
+	}
+	tenant := &capsulev1beta2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{Name: "solar", UID: "memo-tenant", ResourceVersion: "1", Labels: map[string]string{"stage": "dev"}},
+		Status:     capsulev1beta2.TenantStatus{Namespaces: []string{"solar-prod"}, Size: 1},
+	}
+	config := &v1alpha1.ArgoAddonSpec{}
+
+	calls := 0
+	funcMap := ExtraFuncMap()
+	funcMap["renders"] = func() int {
+		calls++
+
+		return calls
+	}
+
+	render := func(tenant *capsulev1beta2.Tenant) string {
+		renderer := NewRenderer(translator, tenant, nil, ConfigContext("", translator, config, tenant, nil, nil), funcMap)
+		cfg, err := renderer.ProjectConfig()
+		assert.NoError(t, err)
+
+		return cfg.ProjectSpec.Description
+	}
+
+	assert.Equal(t, "1 dev", render(tenant))
+
+	// Status and resourceVersion changes are served from the cache
+	updated := tenant.DeepCopy()
+	updated.ResourceVersion = "2"
+	updated.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "capsule"}}
+	updated.Status.State = capsulev1beta2.TenantStateCordoned
+	assert.Equal(t, "1 dev", render(updated))
+	assert.Equal(t, 1, calls)
+
+	// Changed namespaces are rendered again
+	updated.Status.Namespaces = append(updated.Status.Namespaces, "solar-dev")
+	updated.Status.Size = 2
+	assert.Equal(t, "2 dev", render(updated))
+
+	// Changed labels are rendered again
+	updated.Labels["stage"] = "prod"
+	assert.Equal(t, "3 prod", render(updated))
+}
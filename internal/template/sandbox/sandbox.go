@@ -0,0 +1,372 @@
+package sandbox
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	ccaerrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+)
+
+const (
+	// Default maximum duration for the rendering of a single template
+	DefaultTimeout = 5 * time.Second
+
+	// Default maximum size of the output of a single template (1MiB)
+	DefaultMaxOutputSize int64 = 1 << 20
+
+	// Maximum number of elements generated by a single call of until, untilStep or seq
+	MaxSequenceLength = 10000
+
+	// Maximum number of range iterations and template calls of a single rendering
+	MaxIterations = 1000000
+
+	// Function injected into the templates to interrupt the rendering
+	checkFunc = "_sandboxCheck"
+)
+
+// Action calling the check function, inserted into every range body and template
+var checkNode = func() *parse.ActionNode {
+	tmpl := template.Must(template.New("check").Funcs(template.FuncMap{checkFunc: func() string { return "" }}).Parse("{{" + checkFunc + "}}"))
+
+	return tmpl.Tree.Root.Nodes[0].(*parse.ActionNode)
+}()
+
+// Functions which are removed from the function map, unless allowed again with the settings. They either expose
+// the environment of the controller or are not deterministic, which would change the rendered output on every reconcile
+var Restricted = []string{
+	// Environment and network
+	"env",
+	"expandenv",
+	"getHostByName",
+
+	// Time
+	"now",
+	"ago",
+
+	// Random
+	"randAlphaNum",
+	"randAlpha",
+	"randAscii",
+	"randNumeric",
+	"randBytes",
+	"randInt",
+	"shuffle",
+	"uuidv4",
+
+	// Cryptography with random keys, salts or nonces
+	"bcrypt",
+	"htpasswd",
+	"encryptAES",
+	"genPrivateKey",
+	"genCA",
+	"genCAWithKey",
+	"genSelfSignedCert",
+	"genSelfSignedCertWithKey",
+	"genSignedCert",
+	"genSignedCertWithKey",
+}
+
+// Settings of the sandbox
+type Settings struct {
+	// Restricted functions which are allowed again
+	Functions []string
+
+	// Maximum duration for the rendering of a single template
+	Timeout time.Duration
+
+	// Maximum size of the output of a single template in bytes
+	MaxOutputSize int64
+}
+
+var current atomic.Pointer[Settings]
+
+// Configure the sandbox for all following renderings
+func Configure(settings Settings) {
+	current.Store(&settings)
+}
+
+// Current settings of the sandbox, unset limits are defaulted
+func Current() Settings {
+	settings := Settings{}
+	if stored := current.Load(); stored != nil {
+		settings = *stored
+	}
+
+	if settings.Timeout <= 0 {
+		settings.Timeout = DefaultTimeout
+	}
+
+	if settings.MaxOutputSize <= 0 {
+		settings.MaxOutputSize = DefaultMaxOutputSize
+	}
+
+	return settings
+}
+
+// Validate that only restricted functions are allowed again
+func Validate(functions []string) error {
+	for _, name := range functions {
+		if !isRestricted(name) {
+			return fmt.Errorf("function %q is not a restricted template function", name)
+		}
+	}
+
+	return nil
+}
+
+// Restrict removes the restricted functions from the function map, which are not allowed with the current settings.
+// Functions generating sequences or strings are limited, as they allocate before any output is written
+func Restrict(funcMap template.FuncMap) template.FuncMap {
+	allowed := Current().Functions
+
+	for _, name := range Restricted {
+		if !utils.ContainsString(allowed, name) {
+			delete(funcMap, name)
+		}
+	}
+
+	limit(funcMap)
+
+	return funcMap
+}
+
+// Wraps the functions generating sequences or strings, so they fail beyond the limits instead of allocating
+func limit(funcMap template.FuncMap) {
+	if until, ok := funcMap["until"].(func(int) []int); ok {
+		funcMap["until"] = func(count int) ([]int, error) {
+			if err := sequenceLimit(sequenceLength(0, count, 1)); err != nil {
+				return nil, err
+			}
+
+			return until(count), nil
+		}
+	}
+
+	if untilStep, ok := funcMap["untilStep"].(func(int, int, int) []int); ok {
+		funcMap["untilStep"] = func(start, stop, step int) ([]int, error) {
+			if err := sequenceLimit(sequenceLength(start, stop, step)); err != nil {
+				return nil, err
+			}
+
+			return untilStep(start, stop, step), nil
+		}
+	}
+
+	if seq, ok := funcMap["seq"].(func(...int) string); ok {
+		funcMap["seq"] = func(params ...int) (string, error) {
+			// Sequences include the last element
+			var length int
+			switch len(params) {
+			case 1:
+				length = sequenceLength(1, params[0], 1) + 1
+			case 2:
+				length = sequenceLength(params[0], params[1], 1) + 1
+			case 3:
+				length = sequenceLength(params[0], params[2], params[1]) + 1
+			}
+
+			if err := sequenceLimit(length); err != nil {
+				return "", err
+			}
+
+			return seq(params...), nil
+		}
+	}
+
+	if repeat, ok := funcMap["repeat"].(func(int, string) string); ok {
+		funcMap["repeat"] = func(count int, str string) (string, error) {
+			if max := Current().MaxOutputSize; int64(count)*int64(len(str)) > max {
+				return "", &limitError{limit: fmt.Sprintf("output size (%d bytes)", max)}
+			}
+
+			return repeat(count, str), nil
+		}
+	}
+}
+
+// Upper bound of the number of elements from start until stop (excluded)
+func sequenceLength(start, stop, step int) int {
+	if step == 0 {
+		return 0
+	}
+
+	distance := stop - start
+	if distance < 0 {
+		distance = -distance
+	}
+
+	if step < 0 {
+		step = -step
+	}
+
+	return (distance + step - 1) / step
+}
+
+func sequenceLimit(length int) error {
+	if length > MaxSequenceLength {
+		return &limitError{limit: fmt.Sprintf("sequence length (%d elements)", MaxSequenceLength)}
+	}
+
+	return nil
+}
+
+// limitError is returned by the limited functions and reported as exceeded limit of the template
+type limitError struct {
+	limit string
+}
+
+func (e *limitError) Error() string {
+	return "exceeds " + e.limit
+}
+
+// Execute the template within the limits of the current settings. Every range iteration and template call is
+// counted, when the timeout is reached the execution is aborted with the next iteration, template call or write
+func Execute(tmpl *template.Template, data interface{}) ([]byte, error) {
+	settings := Current()
+
+	out := &limitedWriter{max: settings.MaxOutputSize}
+	done := make(chan error, 1)
+
+	instrumented, err := interruptible(tmpl, out)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		done <- instrumented.Execute(out, data)
+	}()
+
+	timer := time.NewTimer(settings.Timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		if out.exceeded {
+			return nil, ccaerrors.NewTemplateLimitExceededError(tmpl.Name(), fmt.Sprintf("output size (%d bytes)", settings.MaxOutputSize))
+		}
+
+		var limit *limitError
+		if errors.As(err, &limit) {
+			return nil, ccaerrors.NewTemplateLimitExceededError(tmpl.Name(), limit.limit)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		return out.Bytes(), nil
+	case <-timer.C:
+		out.abort()
+
+		return nil, ccaerrors.NewTemplateLimitExceededError(tmpl.Name(), fmt.Sprintf("timeout (%s)", settings.Timeout))
+	}
+}
+
+// Copy of the template, which calls the check function at the start of every range iteration and template. The
+// check fails after the rendering was aborted or beyond the maximum number of iterations. The parse trees are
+// copied, as the given template may be shared with other renderings
+func interruptible(tmpl *template.Template, out *limitedWriter) (*template.Template, error) {
+	iterations := 0
+
+	clone, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+
+	clone.Funcs(template.FuncMap{checkFunc: func() (string, error) {
+		if out.stopped.Load() {
+			return "", errAborted
+		}
+
+		iterations++
+		if iterations > MaxIterations {
+			return "", &limitError{limit: fmt.Sprintf("iterations (%d)", MaxIterations)}
+		}
+
+		return "", nil
+	}})
+
+	for _, t := range clone.Templates() {
+		if t.Tree == nil || t.Tree.Root == nil {
+			continue
+		}
+
+		tree := t.Tree.Copy()
+		instrument(tree.Root)
+		tree.Root.Nodes = append([]parse.Node{checkNode.Copy()}, tree.Root.Nodes...)
+
+		if _, err := clone.AddParseTree(t.Name(), tree); err != nil {
+			return nil, err
+		}
+	}
+
+	return clone, nil
+}
+
+// Inserts the check function at the start of the range bodies within the list
+func instrument(list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+
+	for _, node := range list.Nodes {
+		switch n := node.(type) {
+		case *parse.IfNode:
+			instrument(n.List)
+			instrument(n.ElseList)
+		case *parse.WithNode:
+			instrument(n.List)
+			instrument(n.ElseList)
+		case *parse.RangeNode:
+			instrument(n.List)
+			instrument(n.ElseList)
+
+			if n.List != nil {
+				n.List.Nodes = append([]parse.Node{checkNode.Copy()}, n.List.Nodes...)
+			}
+		}
+	}
+}
+
+var errAborted = errors.New("rendering aborted")
+
+// limitedWriter fails writes beyond the maximum size or after it was aborted
+type limitedWriter struct {
+	sync.Mutex
+	bytes.Buffer
+	max      int64
+	exceeded bool
+	stopped  atomic.Bool
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+
+	if w.stopped.Load() {
+		return 0, errAborted
+	}
+
+	if int64(w.Buffer.Len()+len(p)) > w.max {
+		w.exceeded = true
+
+		return 0, fmt.Errorf("output exceeds %d bytes", w.max)
+	}
+
+	return w.Buffer.Write(p)
+}
+
+func (w *limitedWriter) abort() {
+	w.stopped.Store(true)
+}
+
+func isRestricted(name string) bool {
+	return utils.ContainsString(Restricted, name)
+}
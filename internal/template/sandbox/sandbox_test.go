@@ -0,0 +1,124 @@
+package sandbox
+
+import (
+	"runtime"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	ccaerrors "github.com/peak-scale/capsule-argo-addon/internal/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrict(t *testing.T) {
+	defer Configure(Settings{})
+
+	funcMap := Restrict(sprig.TxtFuncMap())
+	for _, name := range Restricted {
+		assert.NotContains(t, funcMap, name)
+	}
+	assert.Contains(t, funcMap, "toString")
+
+	Configure(Settings{Functions: []string{"now"}})
+	funcMap = Restrict(sprig.TxtFuncMap())
+	assert.Contains(t, funcMap, "now")
+	assert.NotContains(t, funcMap, "env")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate([]string{"now", "uuidv4"}))
+	assert.Error(t, Validate([]string{"toYaml"}))
+}
+
+func TestExecute(t *testing.T) {
+	defer Configure(Settings{})
+
+	funcMap := Restrict(sprig.TxtFuncMap())
+
+	tmpl := template.Must(template.New("env").Funcs(funcMap).Parse(`{{ .name | upper }}`))
+	out, err := Execute(tmpl, map[string]string{"name": "solar"})
+	assert.NoError(t, err)
+	assert.Equal(t, "SOLAR", string(out))
+
+	_, err = template.New("env").Funcs(funcMap).Parse(`{{ env "HOME" }}`)
+	assert.Error(t, err)
+
+	// Output size
+	Configure(Settings{MaxOutputSize: 10})
+	tmpl = template.Must(template.New("size").Funcs(funcMap).Parse(`{{ repeat 11 "x" }}`))
+	_, err = Execute(tmpl, nil)
+	limitErr := &ccaerrors.TemplateLimitExceeded{}
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "size", limitErr.Template)
+
+	// Timeout
+	Configure(Settings{Timeout: 10 * time.Millisecond, MaxOutputSize: 1 << 40})
+	tmpl = template.Must(template.New("loop").Funcs(funcMap).Parse(
+		`{{ range until 10000 }}{{ range until 10000 }}.{{ end }}{{ end }}`))
+	_, err = Execute(tmpl, nil)
+	assert.ErrorAs(t, err, &limitErr)
+	assert.Equal(t, "loop", limitErr.Template)
+}
+
+func TestLimit(t *testing.T) {
+	defer Configure(Settings{})
+
+	Configure(Settings{MaxOutputSize: 100})
+	funcMap := Restrict(sprig.TxtFuncMap())
+
+	tests := []struct {
+		template string
+		expected string
+		err      bool
+	}{
+		{template: `{{ until 3 }}`, expected: "[0 1 2]"},
+		{template: `{{ until 100000000 }}`, err: true},
+		{template: `{{ untilStep 0 10 5 }}`, expected: "[0 5]"},
+		{template: `{{ untilStep 0 100000000 1 }}`, err: true},
+		{template: `{{ seq 3 }}`, expected: "1 2 3"},
+		{template: `{{ seq 0 2 100000000 }}`, err: true},
+		{template: `{{ repeat 3 "x" }}`, expected: "xxx"},
+		{template: `{{ repeat 100000000 "x" }}`, err: true},
+		{template: `{{ range 3 }}{{ . }}{{ end }}`, expected: "012"},
+		{template: `{{ range 2000 }}{{ range 1000 }}{{ end }}{{ end }}`, err: true},
+		{template: `{{ define "r" }}{{ template "r" }}{{ end }}{{ template "r" }}`, err: true},
+	}
+
+	for _, tt := range tests {
+		tmpl := template.Must(template.New("limit").Funcs(funcMap).Parse(tt.template))
+		out, err := Execute(tmpl, nil)
+		if tt.err {
+			assert.Error(t, err, tt.template)
+			continue
+		}
+
+		assert.NoError(t, err, tt.template)
+		assert.Equal(t, tt.expected, string(out), tt.template)
+	}
+}
+
+func TestInterrupt(t *testing.T) {
+	defer Configure(Settings{})
+
+	Configure(Settings{Timeout: 10 * time.Millisecond})
+	funcMap := Restrict(sprig.TxtFuncMap())
+
+	before := runtime.NumGoroutine()
+
+	// Loops without output are aborted with the next iteration
+	tmpl := template.Must(template.New("loop").Funcs(funcMap).Parse(
+		`{{ range 100000 }}{{ range 100000 }}{{ $x := 1 }}{{ end }}{{ end }}`))
+	_, err := Execute(tmpl, nil)
+	limitErr := &ccaerrors.TemplateLimitExceeded{}
+	assert.ErrorAs(t, err, &limitErr)
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "timed-out rendering is still running")
+
+	// The shared template is not modified
+	assert.NotContains(t, tmpl.Tree.Root.String(), checkFunc)
+}
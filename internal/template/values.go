@@ -0,0 +1,121 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	"github.com/peak-scale/capsule-argo-addon/internal/utils"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Values of the translator for the tenant. The tenant may override the keys the translator allows via the
+// referenced ConfigMap (key named after the translator) and the annotation, the annotation takes precedence.
+func TranslatorValues(
+	ctx context.Context,
+	c client.Reader,
+	translator *v1alpha1.ArgoTranslator,
+	tenant *capsulev1beta2.Tenant,
+) (map[string]interface{}, error) {
+	values, err := translator.GetValues()
+	if err != nil {
+		return nil, fmt.Errorf("translator %s: %w", translator.Name, err)
+	}
+
+	if len(translator.Spec.OverridableValues) == 0 {
+		return values, nil
+	}
+
+	overrides, err := configMapValues(ctx, c, translator, tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	annotated, err := parseValues(meta.TenantValues(tenant, translator.Name))
+	if err != nil {
+		return nil, fmt.Errorf("invalid values annotation for translator %s: %w", translator.Name, err)
+	}
+
+	return MergeValues(values, translator.Spec.OverridableValues, overrides, annotated)
+}
+
+// Merges the allowed keys (dot separated) of the overrides into the values, later overrides take precedence
+func MergeValues(
+	values map[string]interface{},
+	allowed []string,
+	overrides ...map[string]interface{},
+) (map[string]interface{}, error) {
+	merged := runtime.DeepCopyJSON(values)
+	for _, override := range overrides {
+		for _, key := range allowed {
+			path := strings.Split(key, ".")
+
+			value, found, err := unstructured.NestedFieldCopy(override, path...)
+			if err != nil || !found {
+				continue
+			}
+
+			if err := unstructured.SetNestedField(merged, value, path...); err != nil {
+				return nil, fmt.Errorf("failed to override value %s: %w", key, err)
+			}
+		}
+	}
+
+	return merged, nil
+}
+
+// Values for the translator from the ConfigMap referenced by the tenant
+func configMapValues(
+	ctx context.Context,
+	c client.Reader,
+	translator *v1alpha1.ArgoTranslator,
+	tenant *capsulev1beta2.Tenant,
+) (map[string]interface{}, error) {
+	namespace, name := meta.TenantValuesFrom(tenant)
+	if name == "" {
+		return nil, nil
+	}
+
+	// Tenants may only reference ConfigMaps in their own namespaces
+	if !utils.ContainsString(tenant.Status.Namespaces, namespace) {
+		return nil, fmt.Errorf("values configmap %s/%s is not in a namespace of the tenant", namespace, name)
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, cm); err != nil {
+		return nil, fmt.Errorf("failed to get values configmap %s/%s: %w", namespace, name, err)
+	}
+
+	values, err := parseValues(cm.Data[translator.Name])
+	if err != nil {
+		return nil, fmt.Errorf("invalid values for translator %s in configmap %s/%s: %w", translator.Name, namespace, name, err)
+	}
+
+	return values, nil
+}
+
+// Parses YAML (or JSON) values
+func parseValues(raw string) (map[string]interface{}, error) {
+	values := make(map[string]interface{})
+	if strings.TrimSpace(raw) == "" {
+		return values, nil
+	}
+
+	jsonBytes, err := utils.YamlToJSON([]byte(raw))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(jsonBytes, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -0,0 +1,88 @@
+package template
+
+import (
+	"context"
+	"testing"
+
+	"github.com/peak-scale/capsule-argo-addon/api/v1alpha1"
+	"github.com/peak-scale/capsule-argo-addon/internal/meta"
+	capsulev1beta2 "github.com/projectcapsule/capsule/api/v1beta2"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestMergeValues(t *testing.T) {
+	values := map[string]interface{}{
+		"environment": "dev",
+		"resources": map[string]interface{}{
+			"cpu":    "250m",
+			"memory": "256Mi",
+		},
+	}
+
+	merged, err := MergeValues(values, []string{"environment", "resources.cpu"},
+		map[string]interface{}{
+			"environment": "staging",
+			"resources": map[string]interface{}{
+				"cpu":    "500m",
+				"memory": "1Gi",
+			},
+		},
+		map[string]interface{}{
+			"environment": "prod",
+			"unknown":     true,
+		},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"environment": "prod",
+		"resources": map[string]interface{}{
+			"cpu":    "500m",
+			"memory": "256Mi",
+		},
+	}, merged)
+
+	// Defaults of the translator are not modified
+	assert.Equal(t, "dev", values["environment"])
+}
+
+func TestTranslatorValues(t *testing.T) {
+	translator := &v1alpha1.ArgoTranslator{
+		ObjectMeta: metav1.ObjectMeta{Name: "example"},
+		Spec: v1alpha1.ArgoTranslatorSpec{
+			Values: &runtime.RawExtension{
+				Raw: []byte(`{"environment": "dev", "replicas": 1}`),
+			},
+			OverridableValues: []string{"environment"},
+		},
+	}
+
+	tenant := &capsulev1beta2.Tenant{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "solar",
+			Annotations: map[string]string{
+				meta.AnnotationValuesPrefix + "example": "environment: prod\nreplicas: 3\n",
+			},
+		},
+	}
+
+	values, err := TranslatorValues(context.Background(), nil, translator, tenant)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"environment": "prod",
+		"replicas":    float64(1),
+	}, values)
+
+	// Overrides are ignored, when the translator allows none
+	translator.Spec.OverridableValues = nil
+	values, err = TranslatorValues(context.Background(), nil, translator, tenant)
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", values["environment"])
+
+	// ConfigMaps must be in a namespace of the tenant
+	translator.Spec.OverridableValues = []string{"environment"}
+	tenant.Annotations[meta.AnnotationValuesFrom] = "other/values"
+	_, err = TranslatorValues(context.Background(), nil, translator, tenant)
+	assert.Error(t, err)
+}
@@ -11,6 +11,11 @@ type TenantPermission struct {
 	ClusterRoles []string
 }
 
+// Verify if the tenant is cordoned (no writes in its namespaces)
+func TenantCordoned(tenant *capsulev1beta2.Tenant) bool {
+	return tenant.Spec.Cordoned || tenant.Status.State == capsulev1beta2.TenantStateCordoned
+}
+
 func GetTenantGroups(tenant *capsulev1beta2.Tenant) (groups map[string]TenantPermission) {
 	permissions := GetTenantPermissions(tenant)
 
@@ -181,6 +181,25 @@ func TestGetTenantPermissions(t *testing.T) {
 	}
 }
 
+// TestTenantCordoned tests the TenantCordoned function
+func TestTenantCordoned(t *testing.T) {
+	tenant := &capsulev1beta2.Tenant{}
+	if TenantCordoned(tenant) {
+		t.Errorf("Expected tenant not to be cordoned")
+	}
+
+	tenant.Spec.Cordoned = true
+	if !TenantCordoned(tenant) {
+		t.Errorf("Expected tenant to be cordoned by spec")
+	}
+
+	tenant.Spec.Cordoned = false
+	tenant.Status.State = capsulev1beta2.TenantStateCordoned
+	if !TenantCordoned(tenant) {
+		t.Errorf("Expected tenant to be cordoned by state")
+	}
+}
+
 // Helper function to run tests
 func TestMain(t *testing.M) {
 	t.Run()